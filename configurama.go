@@ -1,10 +1,10 @@
 package configurama
 
 import (
-	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -12,6 +12,7 @@ import (
 // Pool represents a pool of configuration data, divided into named sections.
 type Pool struct {
 	params map[string]map[string]string
+	hooks  []mapstructure.DecodeHookFunc
 }
 
 // Strategy represents a merge strategy, identified by the consts below.
@@ -30,6 +31,14 @@ const (
 	Keep
 )
 
+// NoSectionError represents unknown sections.
+type NoSectionError string
+
+// Error returns the error message for NoSectionError.
+func (s NoSectionError) Error() string {
+	return "unknown section name: " + string(s)
+}
+
 // New returns a new configuration pool containing the given sectioned data.
 func New(params map[string]map[string]string) *Pool {
 	p := Pool{}
@@ -62,7 +71,7 @@ func (p *Pool) ExtractWithHooks(section, prefix string, out interface{}, pre fun
 		}
 	}
 
-	if err = decodeParams(params, out); err != nil {
+	if err = decodeParams(params, out, p.hooks, ""); err != nil {
 		return err
 	}
 
@@ -79,24 +88,97 @@ func (p *Pool) Raw() map[string]map[string]string {
 	return p.params
 }
 
+// Clone returns a deep copy of the pool, including any registered decode hooks. Modifying the clone will not
+// affect the pool that Clone is called from, and vice versa.
+func (p *Pool) Clone() *Pool {
+	params := make(map[string]map[string]string, len(p.params))
+	for sec, keys := range p.params {
+		params[sec] = make(map[string]string, len(keys))
+		for key, val := range keys {
+			params[sec][key] = val
+		}
+	}
+	return &Pool{params: params, hooks: append([]mapstructure.DecodeHookFunc(nil), p.hooks...)}
+}
+
+// SectionNames returns the names of all sections in the pool, sorted alphabetically.
+func (p *Pool) SectionNames() []string {
+	names := make([]string, 0, len(p.params))
+	for name := range p.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Extract attempts to populate the given struct with configuration data from
 // the section with the given name. Names are matched in a fuzzy manner, so for
 // example, all of these names will be matched to the field MySQL:
 // "mysql", "MySQL", "mySQL" and "my_sql".
+// A NoSectionError is returned if the section does not exist.
 func (p *Pool) Extract(section, prefix string, out interface{}) error {
 	params, err := p.extractParams(section, prefix)
 	if err == nil {
-		err = decodeParams(params, out)
+		err = decodeParams(params, out, p.hooks, "")
 	}
 	return err
 }
 
+// ExtractTagged works like Extract, but matches keys to the names given in the
+// struct tag with the given name, i.e. "json", instead of the "mapstructure"
+// tag. This makes it possible to reuse structs that are already annotated,
+// i.e. with `json:"db_host"`. Fields without the tag are matched by name.
+func (p *Pool) ExtractTagged(section, prefix string, out interface{}, tagName string) error {
+	params, err := p.extractParams(section, prefix)
+	if err == nil {
+		err = decodeParams(params, out, p.hooks, tagName)
+	}
+	return err
+}
+
+// ExtractWithDecodeHooks works like Extract, but also populates time.Duration
+// fields from values such as "30s", and time.Time fields from values in the
+// given layout, i.e. time.RFC1123. If layout is empty, time.RFC3339 is used,
+// i.e. "2006-01-02T15:04:05Z07:00". These conversions run after any hooks
+// registered via RegisterDecodeHook, so registered hooks take precedence.
+func (p *Pool) ExtractWithDecodeHooks(section, prefix string, out interface{}, layout string) error {
+	params, err := p.extractParams(section, prefix)
+	if err != nil {
+		return err
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	hooks := append(p.hooks[:len(p.hooks):len(p.hooks)],
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToTimeHookFunc(layout),
+	)
+	return decodeParams(params, out, hooks, "")
+}
+
+// RegisterDecodeHook registers a decode hook which is used by Extract,
+// ExtractTagged, ExtractWithHooks and ExtractWithDecodeHooks to convert
+// parameter values into the types of struct fields. This makes it possible to
+// populate fields of custom types, or types such as net.IP and *url.URL, which
+// can't be populated from strings otherwise. For time.Duration and time.Time
+// fields, ExtractWithDecodeHooks can be used without registering any hooks.
+// Hooks are run in the order they were registered, each receiving the output of
+// the previous one. For example, to populate net.IP fields from values such as
+// "127.0.0.1":
+//
+//	config.RegisterDecodeHook(mapstructure.StringToIPHookFunc())
+//
+// Decode hooks are only available in v1, since v2 doesn't extract into structs.
+func (p *Pool) RegisterDecodeHook(hook mapstructure.DecodeHookFunc) {
+	p.hooks = append(p.hooks, hook)
+}
+
 // extractParams extracts all the parameters from the section with the given
 // name, if it exists, using the given prefix to match keys with struct fields.
 func (p *Pool) extractParams(section, prefix string) (map[string]string, error) {
 	params, ok := p.params[section]
 	if !ok {
-		return params, errors.New("unknown section name: " + section)
+		return params, NoSectionError(section)
 	}
 	if prefix != "" {
 		tmp := make(map[string]string, len(params))
@@ -111,11 +193,16 @@ func (p *Pool) extractParams(section, prefix string) (map[string]string, error)
 }
 
 // decodeParams attempts to fill the given struct "out" with values from the
-// given map. "out" must be passed by reference.
-func decodeParams(params map[string]string, out interface{}) error {
+// given map, using the given decode hooks and struct tag name. An empty tag
+// name means the default "mapstructure" tag. "out" must be passed by reference.
+func decodeParams(params map[string]string, out interface{}, hooks []mapstructure.DecodeHookFunc, tagName string) error {
 	config := &mapstructure.DecoderConfig{
 		WeaklyTypedInput: true,
 		Result:           &out,
+		TagName:          tagName,
+	}
+	if len(hooks) > 0 {
+		config.DecodeHook = mapstructure.ComposeDecodeHookFunc(hooks...)
 	}
 	dec, err := mapstructure.NewDecoder(config)
 	if err != nil {
@@ -269,19 +356,19 @@ func diff(first, second map[string]map[string]string) map[string]map[string]stri
 	return res
 }
 
-// MustPrettyPrint returns a string representation of the given configuration
+// PrettyPrint returns a string representation of the given configuration
 // pool, with section names nested in brackets, and key/value pairs listed
-// line-by-line using the given indentation. It panics if it couldn't generate
-// a valid string.
+// line-by-line using the given indentation. An error is returned if it
+// couldn't generate a valid string.
 // Params names, as well as key names within each section, are sorted
 // alphabetically in order to create deterministic and more comparable output.
-func MustPrettyPrint(pool map[string]map[string]string, indent string) string {
+func PrettyPrint(pool map[string]map[string]string, indent string) (string, error) {
 	var out strings.Builder
+	var err error
 
 	writeString := func(s string) {
-		_, err := out.WriteString(s)
-		if err != nil {
-			panic(err)
+		if err == nil {
+			_, err = out.WriteString(s)
 		}
 	}
 
@@ -303,5 +390,17 @@ func MustPrettyPrint(pool map[string]map[string]string, indent string) string {
 		}
 	}
 
-	return strings.Trim(out.String(), "\n")
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(out.String(), "\n"), nil
+}
+
+// MustPrettyPrint works like PrettyPrint, but panics if it couldn't generate a valid string.
+func MustPrettyPrint(pool map[string]map[string]string, indent string) string {
+	s, err := PrettyPrint(pool, indent)
+	if err != nil {
+		panic(err)
+	}
+	return s
 }
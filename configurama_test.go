@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
 )
 
 // empty is a convenience map that refers to the empty configuration pool.
@@ -95,6 +98,45 @@ func TestRaw(t *testing.T) {
 	}
 }
 
+func TestClone(t *testing.T) {
+	params := map[string]map[string]string{
+		"dev": {"db.host": "localhost", "db.username": "root"},
+	}
+	cnf := New(params)
+	clone := cnf.Clone()
+	if !reflect.DeepEqual(clone.Raw(), params) {
+		t.Errorf("expected clone to equal the original, got %v", clone.Raw())
+	}
+
+	clone.Unset("dev", "db.username")
+	if err := clone.Merge(map[string]map[string]string{"dev": {"db.host": "db.local"}, "prod": {"db.host": "db.example.com"}}, Overwrite); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cnf.Raw(), params) {
+		t.Errorf("expected original to be unaffected by changes to the clone, got %v", cnf.Raw())
+	}
+
+	cnf.Unset("dev", "db.host")
+	if host, _ := clone.Get("dev", "db.host"); host != "db.local" {
+		t.Errorf("expected clone to be unaffected by changes to the original, got %q", host)
+	}
+}
+
+func TestSectionNames(t *testing.T) {
+	cnf := New(map[string]map[string]string{
+		"prod":  {"db.host": "db.example.com"},
+		"dev":   {"db.host": "localhost"},
+		"empty": {},
+	})
+	expected := []string{"dev", "empty", "prod"}
+	if actual := cnf.SectionNames(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected section names %v, got %v", expected, actual)
+	}
+	if actual := New(map[string]map[string]string{}).SectionNames(); len(actual) != 0 {
+		t.Errorf("expected no section names, got %v", actual)
+	}
+}
+
 func TestGet(t *testing.T) {
 	cnf := New(map[string]map[string]string{
 		"dev": {
@@ -201,6 +243,9 @@ func TestExtract(t *testing.T) {
 		if err.Error() != expected {
 			t.Fatalf("expected error to equal %q, got %q", expected, err.Error())
 		}
+		if err != NoSectionError("Bad guy") {
+			t.Fatalf("expected error to equal %v, got %#v", NoSectionError("Bad guy"), err)
+		}
 	})
 
 	t.Run("it correctly extracts all parameters", func(t *testing.T) {
@@ -417,6 +462,151 @@ func TestExtract(t *testing.T) {
 	})
 }
 
+func TestExtractTagged(t *testing.T) {
+	type DB struct {
+		Host string `json:"db_host"`
+		Port int    `json:"db_port"`
+		Name string
+	}
+
+	c := New(map[string]map[string]string{"Database": {
+		"db_host": "localhost",
+		"db_port": "3306",
+		"name":    "shop",
+	}})
+
+	t.Run("it matches keys using the given tag", func(t *testing.T) {
+		var db DB
+		verifyNil(t, c.ExtractTagged("Database", "", &db, "json"))
+		if db.Host != "localhost" || db.Port != 3306 || db.Name != "shop" {
+			t.Errorf("expected all fields to be populated, got %+v", db)
+		}
+	})
+
+	t.Run("it ignores the tag in Extract", func(t *testing.T) {
+		var db DB
+		verifyNil(t, c.Extract("Database", "", &db))
+		if db.Host != "" || db.Port != 0 || db.Name != "shop" {
+			t.Errorf("expected only Name to be populated, got %+v", db)
+		}
+	})
+
+	t.Run("it returns an error for unknown sections", func(t *testing.T) {
+		var db DB
+		if err := c.ExtractTagged("Unknown", "", &db, "json"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestExtractWithDecodeHooks(t *testing.T) {
+	type Server struct {
+		Timeout time.Duration
+		Started time.Time
+		Name    string
+	}
+
+	c := New(map[string]map[string]string{
+		"Server": {
+			"timeout": "30s",
+			"started": "2020-05-17T10:30:00Z",
+			"name":    "api",
+		},
+		"Legacy": {
+			"started": "17 May 20 10:30 UTC",
+		},
+	})
+
+	t.Run("it converts durations and times using the default layout", func(t *testing.T) {
+		var s Server
+		verifyNil(t, c.ExtractWithDecodeHooks("Server", "", &s, ""))
+		if s.Timeout != 30*time.Second {
+			t.Errorf("expected Timeout to equal %s, got %s", 30*time.Second, s.Timeout)
+		}
+		if expected := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC); !s.Started.Equal(expected) {
+			t.Errorf("expected Started to equal %s, got %s", expected, s.Started)
+		}
+		if s.Name != "api" {
+			t.Errorf("expected Name to equal %q, got %q", "api", s.Name)
+		}
+	})
+
+	t.Run("it converts times using the given layout", func(t *testing.T) {
+		var s Server
+		verifyNil(t, c.ExtractWithDecodeHooks("Legacy", "", &s, time.RFC822))
+		if expected := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC); !s.Started.Equal(expected) {
+			t.Errorf("expected Started to equal %s, got %s", expected, s.Started)
+		}
+	})
+
+	t.Run("it returns an error for times in another layout", func(t *testing.T) {
+		var s Server
+		if err := c.ExtractWithDecodeHooks("Legacy", "", &s, ""); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("it returns an error for unknown sections", func(t *testing.T) {
+		var s Server
+		if err := c.ExtractWithDecodeHooks("Unknown", "", &s, ""); err != NoSectionError("Unknown") {
+			t.Fatalf("expected error %v, got %v", NoSectionError("Unknown"), err)
+		}
+	})
+}
+
+func TestRegisterDecodeHook(t *testing.T) {
+	type LogLevel int
+
+	type Server struct {
+		Timeout time.Duration
+		Level   LogLevel
+	}
+
+	levels := map[string]LogLevel{"debug": 0, "info": 1, "error": 2}
+	levelHook := func(from, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String || to != reflect.TypeOf(LogLevel(0)) {
+			return data, nil
+		}
+		level, ok := levels[data.(string)]
+		if !ok {
+			return nil, fmt.Errorf("unknown log level: %q", data)
+		}
+		return level, nil
+	}
+
+	t.Run("it converts values using registered hooks", func(t *testing.T) {
+		var s Server
+
+		c := New(map[string]map[string]string{"Server": {
+			"timeout": "1m30s",
+			"level":   "error",
+		}})
+		c.RegisterDecodeHook(mapstructure.StringToTimeDurationHookFunc())
+		c.RegisterDecodeHook(levelHook)
+
+		verifyNil(t, c.Extract("Server", "", &s))
+		if s.Timeout != 90*time.Second {
+			t.Errorf("expected Timeout to equal %s, got %s", 90*time.Second, s.Timeout)
+		}
+		if s.Level != 2 {
+			t.Errorf("expected Level to equal %d, got %d", 2, s.Level)
+		}
+	})
+
+	t.Run("it returns errors from hooks", func(t *testing.T) {
+		var s Server
+
+		c := New(map[string]map[string]string{"Server": {
+			"level": "verbose",
+		}})
+		c.RegisterDecodeHook(levelHook)
+
+		if err := c.ExtractWithHooks("Server", "", &s, nil, nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
 func TestExtractWithHooks(t *testing.T) {
 	type Contact struct {
 		Name, Gender, Email, Phone, Address, Zip, City, Country string
@@ -955,6 +1145,12 @@ func TestMustPrettyPrint(t *testing.T) {
 			if actual != tc.expected {
 				t.Errorf("expected output to match %q, got %q", tc.expected, actual)
 			}
+
+			actual, err := PrettyPrint(tc.config, "  ")
+			verifyNil(t, err)
+			if actual != tc.expected {
+				t.Errorf("expected PrettyPrint output to match %q, got %q", tc.expected, actual)
+			}
 		})
 	}
 }
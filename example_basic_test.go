@@ -6,8 +6,8 @@ import (
 	"github.com/mkock/configurama"
 )
 
-// Example_Basic provides a basic usage example for package configurama.
-func Example_Basic() {
+// Example_basic provides a basic usage example for package configurama.
+func Example_basic() {
 	config := configurama.New(map[string]map[string]string{
 		"Database": {
 			"Type":     "mysql",
@@ -7,9 +7,9 @@ import (
 	"github.com/mkock/configurama"
 )
 
-// Example_Hooks provides a usage example for package configurama using
+// Example_hooks provides a usage example for package configurama using
 // hooks to validate the configuration parameters.
-func Example_Hooks() {
+func Example_hooks() {
 	config := configurama.New(map[string]map[string]string{
 		"Database": {
 			"type":     "mysql",
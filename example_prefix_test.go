@@ -6,10 +6,10 @@ import (
 	"github.com/mkock/configurama"
 )
 
-// Example_Prefix provides a usage example for package configurama using
+// Example_prefix provides a usage example for package configurama using
 // the "prefix" parameter to match configuration parameters where their
 // names are grouped together using a common prefix.
-func Example_Prefix() {
+func Example_prefix() {
 	config := configurama.New(map[string]map[string]string{
 		"Database": {
 			"db.master.type":     "mysql",
@@ -0,0 +1,65 @@
+package configurama
+
+import "sync/atomic"
+
+// EnableAccessTracking enables counting of reads for each key. Reads are counted for Get and the pool-level
+// getters, but not for Params, which are detached from the pool. Counts can be retrieved via AccessCount, and
+// UsedSubset returns the part of the configuration pool that has been read.
+func (p *Pool) EnableAccessTracking() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accesses == nil {
+		p.accesses = make(map[string]map[string]int)
+	}
+	atomic.StoreUint32(&p.trackAccesses, 1)
+}
+
+// AccessCount returns the number of times the given key in the given section has been read since access tracking
+// was enabled. Reads of keys that don't exist are counted as well.
+func (p *Pool) AccessCount(section, key string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.accesses[section][key]
+}
+
+// UsedSubset returns a new configuration pool containing only the keys, and their sections, that have been read at
+// least once since access tracking was enabled. This is useful for generating a minimal configuration from a real
+// run. The returned pool is empty if access tracking is not enabled.
+func (p *Pool) UsedSubset() *Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	res := make(map[string]map[string]string)
+	for sec, keys := range p.accesses {
+		for key := range keys {
+			val, ok := params[sec][key]
+			if !ok {
+				continue
+			}
+			if res[sec] == nil {
+				res[sec] = make(map[string]string)
+			}
+			res[sec][key] = val
+		}
+	}
+	return newPool(res)
+}
+
+// recordAccess counts a read of the given key in the given section, if access tracking is enabled.
+// The lock is only taken when access tracking is enabled, so reads stay lock-free otherwise.
+func (p *Pool) recordAccess(section, key string) {
+	if atomic.LoadUint32(&p.trackAccesses) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accesses[section] == nil {
+		p.accesses[section] = make(map[string]int)
+	}
+	p.accesses[section][key]++
+}
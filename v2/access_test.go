@@ -0,0 +1,49 @@
+package configurama
+
+import "testing"
+
+func TestAccessTracking(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db":    {"host": "localhost", "port": "3306", "user": "root"},
+		"cache": {"ttl": "1m"},
+		"debug": {"enabled": "true"},
+	})
+
+	// Reads are not counted until access tracking is enabled.
+	_, _ = c.Get("debug", "enabled")
+	c.EnableAccessTracking()
+
+	_, _ = c.String("db", "host")
+	_, _ = c.Int("db", "port")
+	_, _ = c.Int("db", "port")
+	_, _ = c.Get("cache", "ttl")
+	_, _ = c.String("db", "unknown")
+	_, _ = c.String("unknown", "unknown")
+
+	tt := map[string]struct {
+		section, key string
+		expected     int
+	}{
+		"read once":          {"db", "host", 1},
+		"read twice":         {"db", "port", 2},
+		"read via Get":       {"cache", "ttl", 1},
+		"never read":         {"db", "user", 0},
+		"read before enable": {"debug", "enabled", 0},
+		"unknown key":        {"db", "unknown", 1},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if actual := c.AccessCount(tc.section, tc.key); actual != tc.expected {
+				t.Errorf("expected count %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+
+	verifyEqual(t, map[string]map[string]string{
+		"db":    {"host": "localhost", "port": "3306"},
+		"cache": {"ttl": "1m"},
+	}, c.UsedSubset().Raw())
+
+	verifyEqual(t, empty, New(map[string]map[string]string{"db": {"host": "localhost"}}).UsedSubset().Raw())
+}
@@ -0,0 +1,101 @@
+package configurama
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// mutexPool is a reference implementation of the pool's former locking scheme, where every read and write takes
+// the same mutex and writes modify the parameters in place. It's used for comparison in the benchmarks below.
+type mutexPool struct {
+	mu     sync.Mutex
+	params map[string]map[string]string
+}
+
+func (p *mutexPool) Get(section, key string) (value string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	value, ok = p.params[section][key]
+	return
+}
+
+func (p *mutexPool) Set(section, key, value string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sec, ok := p.params[section]
+	if !ok {
+		return NoSectionError(section)
+	}
+	sec[key] = value
+	return nil
+}
+
+func (p *mutexPool) Raw() map[string]map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return copyParams(p.params)
+}
+
+// benchmarkParams returns a configuration with the given number of sections, each containing ten keys.
+func benchmarkParams(sections int) map[string]map[string]string {
+	params := make(map[string]map[string]string, sections)
+	for i := 0; i < sections; i++ {
+		sec := make(map[string]string, 10)
+		for j := 0; j < 10; j++ {
+			sec["key"+strconv.Itoa(j)] = "value" + strconv.Itoa(j)
+		}
+		params["section"+strconv.Itoa(i)] = sec
+	}
+	return params
+}
+
+// readHeavy runs the given read function in parallel, and calls the write function once for every 100 reads.
+func readHeavy(b *testing.B, read func(), write func()) {
+	b.RunParallel(func(pb *testing.PB) {
+		n := 0
+		for pb.Next() {
+			n++
+			if n%100 == 0 {
+				write()
+				continue
+			}
+			read()
+		}
+	})
+}
+
+func BenchmarkGetMutex(b *testing.B) {
+	pool := &mutexPool{params: benchmarkParams(10)}
+	readHeavy(b,
+		func() { _, _ = pool.Get("section5", "key5") },
+		func() { _ = pool.Set("section5", "key5", "changed") },
+	)
+}
+
+func BenchmarkGetCOW(b *testing.B) {
+	pool := New(benchmarkParams(10))
+	readHeavy(b,
+		func() { _, _ = pool.Get("section5", "key5") },
+		func() { _ = pool.Set("section5", "key5", "changed") },
+	)
+}
+
+func BenchmarkRawMutex(b *testing.B) {
+	pool := &mutexPool{params: benchmarkParams(100)}
+	readHeavy(b,
+		func() { _ = pool.Raw() },
+		func() { _ = pool.Set("section5", "key5", "changed") },
+	)
+}
+
+func BenchmarkRawCOW(b *testing.B) {
+	pool := New(benchmarkParams(100))
+	readHeavy(b,
+		func() { _ = pool.Raw() },
+		func() { _ = pool.Set("section5", "key5", "changed") },
+	)
+}
@@ -0,0 +1,108 @@
+package configurama
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema describes the keys expected in each section of a configuration pool, and the options used to validate
+// them, i.e. Require and ValidateRegExp. Keys are validated as if they were fetched with Params.String.
+type Schema map[string]map[string][]Option
+
+// Status represents the outcome of checking a single key, identified by the constants below.
+type Status uint8
+
+const (
+	// StatusOK indicates that the key passed validation, or that it's missing but not required.
+	StatusOK Status = iota
+
+	// StatusMissing indicates that the key is required but missing or empty.
+	StatusMissing
+
+	// StatusInvalid indicates that the key failed validation.
+	StatusInvalid
+)
+
+// String returns the name of the status, i.e. "ok".
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusMissing:
+		return "missing"
+	case StatusInvalid:
+		return "invalid"
+	}
+	return fmt.Sprintf("Status(%d)", uint8(s))
+}
+
+// MarshalText returns the name of the status, so it's rendered as a string when encoded as JSON.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// KeyStatus represents the outcome of checking a single key. Reason is the error message for keys that are
+// missing or invalid, and empty otherwise.
+type KeyStatus struct {
+	Section, Key string
+	Status       Status
+	Reason       string
+}
+
+// CheckReport represents the outcome of checking a configuration pool against a schema, sorted by section and key.
+type CheckReport []KeyStatus
+
+// OK returns true if all keys in the report passed the check.
+func (r CheckReport) OK() bool {
+	for _, ks := range r {
+		if ks.Status != StatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the report as text, with one line per key, i.e. `db.port: invalid (enum validation failed...)`.
+func (r CheckReport) String() string {
+	var b strings.Builder
+	for _, ks := range r {
+		b.WriteString(ks.Section + "." + ks.Key + ": " + ks.Status.String())
+		if ks.Reason != "" {
+			b.WriteString(" (" + ks.Reason + ")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Check validates every key in the given schema and returns a report with the status of each key.
+// Unlike the getters, which return the first error encountered, Check always checks all keys, making it suitable
+// for linting a configuration. Missing sections are treated the same way as missing keys.
+func (p *Pool) Check(schema Schema) CheckReport {
+	params := p.load()
+	report := make(CheckReport, 0)
+	for sec, keys := range schema {
+		for key, options := range keys {
+			ks := KeyStatus{Section: sec, Key: key}
+			_, err := Params(params[sec]).String(key, options...)
+			var noKeyErr NoKeyError
+			switch {
+			case errors.As(err, &noKeyErr):
+				ks.Status, ks.Reason = StatusMissing, err.Error()
+			case err != nil:
+				ks.Status, ks.Reason = StatusInvalid, err.Error()
+			}
+			report = append(report, ks)
+		}
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Section != report[j].Section {
+			return report[i].Section < report[j].Section
+		}
+		return report[i].Key < report[j].Key
+	})
+	return report
+}
@@ -0,0 +1,62 @@
+package configurama
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db": {
+			"host": "localhost",
+			"port": "abc",
+			"mode": "fast",
+		},
+	})
+
+	schema := Schema{
+		"db": {
+			"host": {Require()},
+			"port": {Require(), ValidateIntegral()},
+			"mode": {ValidateEnum([]string{"fast", "slow"})},
+			"user": {Require()},
+			"name": {},
+		},
+		"cache": {
+			"ttl": {Require()},
+		},
+	}
+
+	expected := CheckReport{
+		{"cache", "ttl", StatusMissing, NoKeyError("ttl").Error()},
+		{"db", "host", StatusOK, ""},
+		{"db", "mode", StatusOK, ""},
+		{"db", "name", StatusOK, ""},
+		{"db", "port", StatusInvalid, RegExpValidationError("port").Error()},
+		{"db", "user", StatusMissing, NoKeyError("user").Error()},
+	}
+
+	report := c.Check(schema)
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected report %v, got %v", expected, report)
+	}
+	if report.OK() {
+		t.Error("expected report not to be OK")
+	}
+
+	report = c.Check(Schema{"db": {"host": {Require(), ValidateRegExp(regexp.MustCompile(`^[a-z]+$`))}}})
+	if !report.OK() {
+		t.Errorf("expected report to be OK, got %v", report)
+	}
+	if s := report.String(); s != "db.host: ok\n" {
+		t.Errorf("expected %q, got %q", "db.host: ok\n", s)
+	}
+
+	b, err := json.Marshal(report)
+	verifyNil(t, err)
+	if s := string(b); s != `[{"Section":"db","Key":"host","Status":"ok","Reason":""}]` {
+		t.Errorf("unexpected JSON: %s", s)
+	}
+}
@@ -0,0 +1,44 @@
+package configurama
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// ValidateEmbeddedChecksum validates a parameter with an embedded checksum of itself, in the form
+// "<payload><sep><checksum>", i.e. "secret#5ca2e8e5" with the separator "#". The checksum is the hexadecimal digest
+// of the payload using the given algorithm, which is either "crc32" (IEEE) or "sha256". The value is split on the
+// last occurrence of sep, and on success, the getter returns the payload without the separator and checksum.
+// The checksum is verified before any validation options are applied, so these apply to the payload.
+// A ChecksumValidationError is returned if the separator is missing, the checksum doesn't match, or the
+// algorithm is unknown.
+var ValidateEmbeddedChecksum = func(sep, algo string) Option {
+	return func(o *option) {
+		o.transforms = append(o.transforms, func(key, value string) (string, error) {
+			i := strings.LastIndex(value, sep)
+			if sep == "" || i < 0 {
+				return "", ChecksumValidationError{key, "missing checksum"}
+			}
+			payload, digest := value[:i], value[i+len(sep):]
+
+			var expected string
+			switch algo {
+			case "crc32":
+				expected = fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(payload)))
+			case "sha256":
+				sum := sha256.Sum256([]byte(payload))
+				expected = hex.EncodeToString(sum[:])
+			default:
+				return "", ChecksumValidationError{key, fmt.Sprintf("unknown algorithm %q", algo)}
+			}
+
+			if !strings.EqualFold(digest, expected) {
+				return "", ChecksumValidationError{key, "checksum mismatch"}
+			}
+			return payload, nil
+		})
+	}
+}
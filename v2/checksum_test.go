@@ -0,0 +1,46 @@
+package configurama
+
+import "testing"
+
+func TestValidateEmbeddedChecksum(t *testing.T) {
+	sec := Params{
+		"crc32":      "secret#5ca2e8e5",
+		"crc32Upper": "secret#5CA2E8E5",
+		"sha256":     "8080|6c237681e70921603a306be9a1a5d9833fce5c1e268f52b1650970eaad0dce21",
+		"tampered":   "secre7#5ca2e8e5",
+		"embedded":   "a#b#5ca2e8e5",
+		"missing":    "secret",
+	}
+
+	tt := map[string]struct {
+		key, sep, algo, expected string
+		err                      error
+	}{
+		"crc32":              {"crc32", "#", "crc32", "secret", nil},
+		"crc32, uppercase":   {"crc32Upper", "#", "crc32", "secret", nil},
+		"sha256":             {"sha256", "|", "sha256", "8080", nil},
+		"tampered":           {"tampered", "#", "crc32", "", ChecksumValidationError{"tampered", "checksum mismatch"}},
+		"separator in value": {"embedded", "#", "crc32", "", ChecksumValidationError{"embedded", "checksum mismatch"}},
+		"missing checksum":   {"missing", "#", "crc32", "", ChecksumValidationError{"missing", "missing checksum"}},
+		"unknown algorithm":  {"crc32", "#", "md5", "", ChecksumValidationError{"crc32", `unknown algorithm "md5"`}},
+		"missing key":        {"unknown", "#", "crc32", "", nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.String(tc.key, ValidateEmbeddedChecksum(tc.sep, tc.algo))
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+
+	port, err := sec.Int("sha256", ValidateEmbeddedChecksum("|", "sha256"), ValidateIntegral())
+	verifyNil(t, err)
+	if port != 8080 {
+		t.Errorf("expected value %d, got %d", 8080, port)
+	}
+}
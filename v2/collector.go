@@ -0,0 +1,243 @@
+package configurama
+
+import (
+	"net"
+	"net/url"
+	"time"
+)
+
+// ErrorCollector wraps Params and provides the same getters, but instead of returning errors, they are collected
+// and can be retrieved in one go via Err. This makes it less verbose to fetch a series of keys:
+//
+//	c := NewErrorCollector(section)
+//	host := c.String("db.host", Require())
+//	port := c.Int("db.port", Default("3306"))
+//	if err := c.Err(); err != nil {
+//		// Handle all errors at once.
+//	}
+//
+// Getters return the zero value of their type for keys that fail. An ErrorCollector is not concurrency-safe.
+type ErrorCollector struct {
+	params Params
+	errs   []error
+}
+
+// NewErrorCollector returns a new ErrorCollector for the given Params.
+func NewErrorCollector(params Params) *ErrorCollector {
+	return &ErrorCollector{params: params}
+}
+
+// Err returns a MultiError containing all the errors collected so far, or nil if there were none.
+func (c *ErrorCollector) Err() error {
+	return multiError(c.errs)
+}
+
+// collect stores the given error, if any.
+func (c *ErrorCollector) collect(err error) {
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+// String returns the string value for the given key, see Params.String.
+func (c *ErrorCollector) String(key string, options ...Option) string {
+	val, err := c.params.String(key, options...)
+	c.collect(err)
+	return val
+}
+
+// Strings returns the string values for the given key, see Params.Strings.
+func (c *ErrorCollector) Strings(key, separator string, options ...Option) []string {
+	vals, err := c.params.Strings(key, separator, options...)
+	c.collect(err)
+	return vals
+}
+
+// HostPorts returns the value for the given key as "host:port" endpoints, see Params.HostPorts.
+func (c *ErrorCollector) HostPorts(key, separator string, options ...Option) []string {
+	vals, err := c.params.HostPorts(key, separator, options...)
+	c.collect(err)
+	return vals
+}
+
+// StringMap returns the value for the given key as a map, see Params.StringMap.
+func (c *ErrorCollector) StringMap(key, pairSep, kvSep string, options ...Option) map[string]string {
+	vals, err := c.params.StringMap(key, pairSep, kvSep, options...)
+	c.collect(err)
+	return vals
+}
+
+// Slug returns the value for the given key normalized to a slug, see Params.Slug.
+func (c *ErrorCollector) Slug(key string, options ...Option) string {
+	slug, err := c.params.Slug(key, options...)
+	c.collect(err)
+	return slug
+}
+
+// UUID returns the value for the given key as a normalized UUID, see Params.UUID.
+func (c *ErrorCollector) UUID(key string, options ...Option) string {
+	uuid, err := c.params.UUID(key, options...)
+	c.collect(err)
+	return uuid
+}
+
+// URL returns the value for the given key as an absolute URL, see Params.URL.
+func (c *ErrorCollector) URL(key string, options ...Option) *url.URL {
+	u, err := c.params.URL(key, options...)
+	c.collect(err)
+	return u
+}
+
+// IP returns the value for the given key as an IP address, see Params.IP.
+func (c *ErrorCollector) IP(key string, options ...Option) net.IP {
+	ip, err := c.params.IP(key, options...)
+	c.collect(err)
+	return ip
+}
+
+// LanguageTag returns the value for the given key as a canonical BCP 47 language tag, see Params.LanguageTag.
+func (c *ErrorCollector) LanguageTag(key string, options ...Option) string {
+	tag, err := c.params.LanguageTag(key, options...)
+	c.collect(err)
+	return tag
+}
+
+// Int returns the value for the given key as an int, see Params.Int.
+func (c *ErrorCollector) Int(key string, options ...Option) int {
+	i, err := c.params.Int(key, options...)
+	c.collect(err)
+	return i
+}
+
+// Int8 returns the value for the given key as an int8, see Params.Int8.
+func (c *ErrorCollector) Int8(key string, options ...Option) int8 {
+	i, err := c.params.Int8(key, options...)
+	c.collect(err)
+	return i
+}
+
+// Int16 returns the value for the given key as an int16, see Params.Int16.
+func (c *ErrorCollector) Int16(key string, options ...Option) int16 {
+	i, err := c.params.Int16(key, options...)
+	c.collect(err)
+	return i
+}
+
+// Int32 returns the value for the given key as an int32, see Params.Int32.
+func (c *ErrorCollector) Int32(key string, options ...Option) int32 {
+	i, err := c.params.Int32(key, options...)
+	c.collect(err)
+	return i
+}
+
+// Int64 returns the value for the given key as an int64, see Params.Int64.
+func (c *ErrorCollector) Int64(key string, options ...Option) int64 {
+	i, err := c.params.Int64(key, options...)
+	c.collect(err)
+	return i
+}
+
+// IntBase returns the value for the given key as an int64 in the given base, see Params.IntBase.
+func (c *ErrorCollector) IntBase(key string, base int, options ...Option) int64 {
+	i, err := c.params.IntBase(key, base, options...)
+	c.collect(err)
+	return i
+}
+
+// Port returns the value for the given key as a port number, see Params.Port.
+func (c *ErrorCollector) Port(key string, options ...Option) uint16 {
+	port, err := c.params.Port(key, options...)
+	c.collect(err)
+	return port
+}
+
+// Uint8 returns the value for the given key as a uint8, see Params.Uint8.
+func (c *ErrorCollector) Uint8(key string, options ...Option) uint8 {
+	u, err := c.params.Uint8(key, options...)
+	c.collect(err)
+	return u
+}
+
+// Uint16 returns the value for the given key as a uint16, see Params.Uint16.
+func (c *ErrorCollector) Uint16(key string, options ...Option) uint16 {
+	u, err := c.params.Uint16(key, options...)
+	c.collect(err)
+	return u
+}
+
+// Uint32 returns the value for the given key as a uint32, see Params.Uint32.
+func (c *ErrorCollector) Uint32(key string, options ...Option) uint32 {
+	u, err := c.params.Uint32(key, options...)
+	c.collect(err)
+	return u
+}
+
+// Uint64 returns the value for the given key as a uint64, see Params.Uint64.
+func (c *ErrorCollector) Uint64(key string, options ...Option) uint64 {
+	u, err := c.params.Uint64(key, options...)
+	c.collect(err)
+	return u
+}
+
+// Float returns the value for the given key as a float64, see Params.Float.
+func (c *ErrorCollector) Float(key string, options ...Option) float64 {
+	f, err := c.params.Float(key, options...)
+	c.collect(err)
+	return f
+}
+
+// Bytes returns the value for the given key as a number of bytes, see Params.Bytes.
+func (c *ErrorCollector) Bytes(key string, options ...Option) int64 {
+	b, err := c.params.Bytes(key, options...)
+	c.collect(err)
+	return b
+}
+
+// Bool returns the value for the given key as a bool, see Params.Bool.
+func (c *ErrorCollector) Bool(key string, options ...Option) bool {
+	b, err := c.params.Bool(key, options...)
+	c.collect(err)
+	return b
+}
+
+// BoolPtr returns the value for the given key as a pointer to a bool, see Params.BoolPtr.
+func (c *ErrorCollector) BoolPtr(key string, options ...Option) *bool {
+	b, err := c.params.BoolPtr(key, options...)
+	c.collect(err)
+	return b
+}
+
+// Duration returns the value for the given key as a time.Duration, see Params.Duration.
+func (c *ErrorCollector) Duration(key string, options ...Option) time.Duration {
+	d, err := c.params.Duration(key, options...)
+	c.collect(err)
+	return d
+}
+
+// Time returns the value for the given key as a time.Time, see Params.Time.
+func (c *ErrorCollector) Time(key, format string, options ...Option) time.Time {
+	t, err := c.params.Time(key, format, options...)
+	c.collect(err)
+	return t
+}
+
+// TimeMulti returns the value for the given key as a time.Time using the first matching format, see Params.TimeMulti.
+func (c *ErrorCollector) TimeMulti(key string, formats []string, options ...Option) time.Time {
+	t, err := c.params.TimeMulti(key, formats, options...)
+	c.collect(err)
+	return t
+}
+
+// RelativeTime returns the value for the given key as a time.Time relative to base, see Params.RelativeTime.
+func (c *ErrorCollector) RelativeTime(key string, base time.Time, options ...Option) time.Time {
+	t, err := c.params.RelativeTime(key, base, options...)
+	c.collect(err)
+	return t
+}
+
+// Deadline returns the value for the given key as a deadline relative to the current time, see Params.Deadline.
+func (c *ErrorCollector) Deadline(key string, options ...Option) time.Time {
+	t, err := c.params.Deadline(key, options...)
+	c.collect(err)
+	return t
+}
@@ -0,0 +1,63 @@
+package configurama
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestErrorCollector(t *testing.T) {
+	sec := Params{
+		"host":    "localhost",
+		"port":    "3306",
+		"timeout": "5s",
+		"debug":   "yes",
+		"ratio":   "invalid",
+		"tags":    "a,b",
+	}
+
+	t.Run("no errors", func(t *testing.T) {
+		c := NewErrorCollector(sec)
+		host := c.String("host", Require())
+		port := c.Int("port")
+		timeout := c.Duration("timeout")
+		debug := c.Bool("debug")
+		tags := c.Strings("tags", ",")
+		if err := c.Err(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if host != "localhost" || port != 3306 || timeout != 5*time.Second || !debug || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+			t.Errorf("unexpected values: %q, %d, %s, %t, %v", host, port, timeout, debug, tags)
+		}
+	})
+
+	t.Run("multiple errors", func(t *testing.T) {
+		c := NewErrorCollector(sec)
+		user := c.String("user", Require())
+		port := c.Uint8("port")
+		ratio := c.Float("ratio")
+		host := c.String("host")
+
+		if user != "" || port != 0 || ratio != 0 {
+			t.Errorf("expected zero values for failed keys, got %q, %d, %f", user, port, ratio)
+		}
+		if host != "localhost" {
+			t.Errorf("expected value %q, got %q", "localhost", host)
+		}
+
+		err := c.Err()
+		var multi MultiError
+		if !errors.As(err, &multi) {
+			t.Fatalf("expected a MultiError, got %v", err)
+		}
+		expected := MultiError{
+			NoKeyError("user"),
+			ConversionError{"port", "3306", "uint8"},
+			ConversionError{"ratio", "invalid", "float64"},
+		}
+		if !reflect.DeepEqual(multi, expected) {
+			t.Errorf("expected errors %v, got %v", expected, multi)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package configurama
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultCommandTimeout is the time that ValidateCommand allows a command to run before it's killed.
+const DefaultCommandTimeout = 10 * time.Second
+
+// ValidateCommand validates a parameter by running an external command, with the parameter value piped to the
+// command's standard input. The command and its arguments are passed as-is to os/exec, no shell is involved.
+// Validation fails if the command can't be started, exits with a non-zero exit code or runs for longer than
+// DefaultCommandTimeout, in which case a CommandValidationError is returned containing the command's standard
+// error output or the reason for the failure. Use ValidateCommandTimeout for a different timeout.
+// Note that this executes an external process every time the parameter is fetched, so it should only be used
+// with trusted commands, and preferably for parameters that are fetched once.
+var ValidateCommand = func(name string, args ...string) Option {
+	return ValidateCommandTimeout(DefaultCommandTimeout, name, args...)
+}
+
+// ValidateCommandTimeout validates a parameter by running an external command, like ValidateCommand, but kills the
+// command if it runs for longer than the given timeout, failing validation. A timeout of zero or less means that
+// there's no timeout.
+var ValidateCommandTimeout = func(timeout time.Duration, name string, args ...string) Option {
+	return func(o *option) {
+		o.validate("command", func(key, value string) error {
+			ctx := context.Background()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			var stderr bytes.Buffer
+			cmd := exec.CommandContext(ctx, name, args...)
+			cmd.Stdin = strings.NewReader(value)
+			cmd.Stderr = &stderr
+			if err := cmd.Run(); err != nil {
+				reason := strings.TrimSpace(stderr.String())
+				if ctx.Err() == context.DeadlineExceeded {
+					reason = "timed out after " + timeout.String()
+				} else if reason == "" {
+					reason = err.Error()
+				}
+				return CommandValidationError{key, name, reason}
+			}
+			return nil
+		})
+	}
+}
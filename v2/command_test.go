@@ -0,0 +1,63 @@
+package configurama
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestValidateCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tt := map[string]struct {
+		value    string
+		option   Option
+		expected error
+	}{
+		"command succeeds": {
+			"allowed", ValidateCommand("sh", "-c", "grep -q '^allowed$'"), nil,
+		},
+		"command fails with stderr": {
+			"denied", ValidateCommand("sh", "-c", "echo 'policy violation' >&2; exit 1"), CommandValidationError{"x", "sh", "policy violation"},
+		},
+		"command fails without stderr": {
+			"denied", ValidateCommand("sh", "-c", "exit 3"), CommandValidationError{"x", "sh", "exit status 3"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := checkApplyOptions("x", tc.value, true, tc.option)
+			if err != tc.expected {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	_, err := checkApplyOptions("x", "value", true, ValidateCommand("configurama-no-such-command"))
+	var cmdErr CommandValidationError
+	if !errors.As(err, &cmdErr) {
+		t.Errorf("expected CommandValidationError for unknown command, got %v", err)
+	}
+}
+
+func TestValidateCommandTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	start := time.Now()
+	_, err := checkApplyOptions("x", "value", true, ValidateCommandTimeout(50*time.Millisecond, "sleep", "10"))
+	if expected := (CommandValidationError{"x", "sleep", "timed out after 50ms"}); err != expected {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected command to be killed after the timeout, took %s", elapsed)
+	}
+
+	_, err = checkApplyOptions("x", "value", true, ValidateCommandTimeout(0, "sleep", "0"))
+	verifyNil(t, err)
+}
@@ -0,0 +1,86 @@
+package configurama
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MapValuesConcurrent replaces every value in the pool with the value returned by fn, calling fn from the given
+// number of goroutines. This speeds up expensive per-value operations such as fetching secrets or remote includes
+// for large pools. A worker count less than one is treated as one.
+// Values are read from a snapshot of the pool, and fn is called without holding the lock. Once all values have
+// been processed, the results are applied at once, so readers see either all or none of the new values. Keys that
+// are changed while fn is called, i.e. via Set or Merge, keep their new values.
+// If fn returns an error for any value, a MultiError is returned containing an error for each failing value, ordered
+// by section and key, and the pool is left unmodified. If the context is cancelled before all values have been
+// processed, no more calls to fn are made, the context's error is returned and the pool is left unmodified.
+func (p *Pool) MapValuesConcurrent(ctx context.Context, workers int, fn func(section, key, value string) (string, error)) error {
+	type entry struct {
+		section, key, value, result string
+		err                         error
+	}
+
+	entries := make([]entry, 0)
+	for sec, params := range p.load() {
+		for key, val := range params {
+			entries = append(entries, entry{section: sec, key: key, value: val})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].section != entries[j].section {
+			return entries[i].section < entries[j].section
+		}
+		return entries[i].key < entries[j].key
+	})
+
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				e := &entries[i]
+				e.result, e.err = fn(e.section, e.key, e.value)
+			}
+		}()
+	}
+
+send:
+	for i := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break send
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+	values := make([]resolvedValue, 0, len(entries))
+	for _, e := range entries {
+		if e.err != nil {
+			errs = append(errs, fmt.Errorf("section %q, key %q: %w", e.section, e.key, e.err))
+			continue
+		}
+		values = append(values, resolvedValue{section: e.section, key: e.key, from: e.value, to: e.result})
+	}
+	if len(errs) > 0 {
+		return MultiError(errs)
+	}
+
+	p.applyResolved(values)
+	return nil
+}
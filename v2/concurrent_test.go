@@ -0,0 +1,92 @@
+package configurama
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMapValuesConcurrent(t *testing.T) {
+	t.Run("it transforms all values", func(t *testing.T) {
+		c := New(map[string]map[string]string{
+			"db":  {"host": "localhost", "user": "admin"},
+			"api": {"url": "example.com"},
+		})
+
+		var calls int32
+		err := c.MapValuesConcurrent(context.Background(), 4, func(section, key, value string) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return strings.ToUpper(value), nil
+		})
+		verifyNil(t, err)
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+		verifyEqual(t, c.Raw(), map[string]map[string]string{
+			"db":  {"host": "LOCALHOST", "user": "ADMIN"},
+			"api": {"url": "EXAMPLE.COM"},
+		})
+	})
+
+	t.Run("it keeps values changed while mapping", func(t *testing.T) {
+		c := New(map[string]map[string]string{
+			"db": {"host": "localhost", "user": "admin"},
+		})
+
+		err := c.MapValuesConcurrent(context.Background(), 1, func(section, key, value string) (string, error) {
+			if key == "host" {
+				verifyNil(t, c.Set("db", "user", "root"))
+			}
+			return strings.ToUpper(value), nil
+		})
+		verifyNil(t, err)
+		verifyEqual(t, c.Raw(), map[string]map[string]string{
+			"db": {"host": "LOCALHOST", "user": "root"},
+		})
+	})
+
+	t.Run("it leaves the pool unmodified on errors", func(t *testing.T) {
+		params := map[string]map[string]string{
+			"db": {"host": "localhost", "user": "admin", "password": "secret"},
+		}
+		c := New(params)
+
+		errFailed := errors.New("failed")
+		err := c.MapValuesConcurrent(context.Background(), 0, func(section, key, value string) (string, error) {
+			if key == "host" {
+				return value, nil
+			}
+			return "", errFailed
+		})
+		var multiErr MultiError
+		if !errors.As(err, &multiErr) || len(multiErr) != 2 {
+			t.Fatalf("expected MultiError with two errors, got %v", err)
+		}
+		if !errors.Is(multiErr[0], errFailed) || !strings.Contains(multiErr[0].Error(), `key "password"`) {
+			t.Errorf("expected first error for key password, got %v", multiErr[0])
+		}
+		verifyEqual(t, c.Raw(), map[string]map[string]string{
+			"db": {"host": "localhost", "user": "admin", "password": "secret"},
+		})
+	})
+
+	t.Run("it respects context cancellation", func(t *testing.T) {
+		c := New(map[string]map[string]string{
+			"db": {"host": "localhost", "user": "admin"},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := c.MapValuesConcurrent(ctx, 2, func(section, key, value string) (string, error) {
+			return "changed", nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		verifyEqual(t, c.Raw(), map[string]map[string]string{
+			"db": {"host": "localhost", "user": "admin"},
+		})
+	})
+}
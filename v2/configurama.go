@@ -2,48 +2,123 @@ package configurama
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 func init() {
 	integralRegExp = regexp.MustCompile(`^[0-9]*$`)
+	uuidRegExp = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	unresolvedRegExp = regexp.MustCompile(`\$\{[^}]*\}`)
 }
 
+// unresolvedRegExp is the regular expression used to find unresolved interpolation tokens, i.e. "${host}".
+var unresolvedRegExp *regexp.Regexp
+
 var (
 	// Default sets a default value that will be returned for empty parameters.
 	Default = func(val string) Option { return func(o *option) { o.defaultValue = val } }
 
+	// DefaultFunc sets a function that computes a default value for empty parameters, i.e. from the hostname or an
+	// environment variable. The function is only called if the parameter is empty and no other default applies,
+	// and the value it returns is validated like any other default. The order of precedence is: the parameter's
+	// own value, then the value from WithDefaults, then the value from Default, and finally the value from
+	// DefaultFunc. An error returned by the function is returned as-is by the getters.
+	DefaultFunc = func(fn func(key string) (string, error)) Option { return func(o *option) { o.defaultFunc = fn } }
+
+	// WithDefaults sets a section of default values. For empty parameters, the value for the same key in the
+	// given Params is used instead, which makes it possible to keep defaults in a dedicated section rather than
+	// repeating them at every call site. The order of precedence is: the parameter's own value, then the value
+	// from WithDefaults, and finally the value from Default.
+	WithDefaults = func(d Params) Option { return func(o *option) { o.defaults = d } }
+
+	// FallbackSection sets the name of a section to consult for empty parameters. It's only honored by the
+	// pool-level getters, i.e. Pool.String, since Params are detached from the pool. The value from the fallback
+	// section takes precedence over values from WithDefaults and Default.
+	FallbackSection = func(name string) Option { return func(o *option) { o.fallback = &name } }
+
+	// DefaultBySection sets default values by section name, i.e. a default port of "3306" for the section "mysql"
+	// and "5432" for the section "postgres". It's only honored by the pool-level getters, i.e. Pool.Int, since
+	// Params are detached from the pool. For sections present in the map, the value takes precedence over Default.
+	DefaultBySection = func(m map[string]string) Option { return func(o *option) { o.sectionDefaults = m } }
+
 	// Require sets a parameter as required. Empty parameters will cause an error to be returned when fetched.
 	Require = func() Option { return func(o *option) { o.require = true } }
 
 	// ValidateRegExp validates a parameter against a regular expression. Mismatches will cause an error
 	// to be returned when fetched.
-	ValidateRegExp = func(regex *regexp.Regexp) Option { return func(o *option) { o.validateRegExp = regex } }
+	ValidateRegExp = func(regex *regexp.Regexp) Option {
+		return func(o *option) { o.validate("regexp", validateRegExp(regex)) }
+	}
+
+	// ValidateAnyRegExp validates a parameter against several regular expressions, of which at least one must match.
+	// A RegExpValidationError is returned if none of them match.
+	ValidateAnyRegExp = func(patterns ...*regexp.Regexp) Option {
+		return func(o *option) {
+			o.validate("anyregexp", func(key, value string) error {
+				for _, regex := range patterns {
+					if regex.MatchString(value) {
+						return nil
+					}
+				}
+				return RegExpValidationError(key)
+			})
+		}
+	}
+
+	// ValidateAllRegExp validates a parameter against several regular expressions, which must all match.
+	// A RegExpValidationError is returned if any of them doesn't match.
+	ValidateAllRegExp = func(patterns ...*regexp.Regexp) Option {
+		return func(o *option) {
+			o.validate("allregexp", func(key, value string) error {
+				for _, regex := range patterns {
+					if !regex.MatchString(value) {
+						return RegExpValidationError(key)
+					}
+				}
+				return nil
+			})
+		}
+	}
 
 	// integralRegExp is the regular expression used to validate integrals.
 	integralRegExp *regexp.Regexp
 
 	// ValidateIntegral validates a parameter as an integral (integer).
-	ValidateIntegral = func() Option { return func(o *option) { o.validateRegExp = integralRegExp } }
+	ValidateIntegral = func() Option {
+		return func(o *option) { o.validate("integral", validateRegExp(integralRegExp)) }
+	}
 
 	// ValidateFunc validates a parameter against the given function.
 	// If the function returns a non-nil error, validation fails, and the original error will be returned unwrapped.
 	ValidateFunc = func(validateFunc func(key, value string) error) Option {
 		return func(o *option) {
-			o.validateFunc = validateFunc
+			o.validate("func", validateFunc)
 		}
 	}
 
+	// NonZero marks a numeric parameter as non-zero. All numeric getters (Int, Int8 through Int64, IntBase, Uint8
+	// through Uint64, Float, Bytes and Duration) return a ZeroValueError if the resulting value is zero, including
+	// for missing/empty parameters unless a non-zero default is given. Port never returns zero.
+	NonZero = func() Option { return func(o *option) { o.nonZero = true } }
+
 	// ValidateEnum validates a parameter against a slice of strings.
 	// If the parameter doesn't match one of the strings, an EnumValidationError is returned.
 	ValidateEnum = func(values []string) Option {
 		return func(o *option) {
-			o.validateFunc = func(key, value string) error {
+			o.validate("enum", func(key, value string) error {
 				if len(values) == 0 {
 					return nil
 				}
@@ -53,7 +128,261 @@ var (
 					}
 				}
 				return EnumValidationError(key)
-			}
+			})
+		}
+	}
+
+	// ValidateEnumFold validates a parameter against a slice of strings like ValidateEnum, but compares them
+	// case-insensitively, i.e. "MySQL" matches "mysql". Matching parameters are normalized to the casing used in
+	// the slice, so String returns "mysql" for "MYSQL" with the values {"mysql", "postgres"}. Like Transform, the
+	// normalization happens before other validators run. If the parameter doesn't match one of the strings, an
+	// EnumValidationError is returned.
+	ValidateEnumFold = func(values []string) Option {
+		return func(o *option) {
+			o.transforms = append(o.transforms, func(key, value string) (string, error) {
+				for _, val := range values {
+					if strings.EqualFold(value, val) {
+						return val, nil
+					}
+				}
+				return value, nil
+			})
+			o.validate("enumFold", func(key, value string) error {
+				if len(values) == 0 {
+					return nil
+				}
+				for _, val := range values {
+					if value == val {
+						return nil
+					}
+				}
+				return EnumValidationError(key)
+			})
+		}
+	}
+
+	// ValidateNotIn validates that a parameter doesn't match any of the given forbidden values, i.e. placeholder
+	// credentials such as "changeme". It's the inverse of ValidateEnum. If ignoreCase is true, values are matched
+	// case-insensitively. A ForbiddenValueError is returned if the parameter matches a forbidden value. The error
+	// doesn't contain the value, so it's safe to use with credentials.
+	ValidateNotIn = func(forbidden []string, ignoreCase bool) Option {
+		return func(o *option) {
+			o.validate("notIn", func(key, value string) error {
+				for _, val := range forbidden {
+					if value == val || ignoreCase && strings.EqualFold(value, val) {
+						return ForbiddenValueError(key)
+					}
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidateSubsetOf validates each element of a list parameter against a slice of allowed strings.
+	// Unlike ValidateEnum, which validates the value as a whole, it's applied by getters returning slices, i.e.
+	// Strings, after splitting the value into elements. Other getters ignore it.
+	// If an element doesn't match one of the strings, a SubsetValidationError naming the element is returned.
+	ValidateSubsetOf = func(allowed []string) Option {
+		return func(o *option) {
+			o.validateElement("subset", func(key, value string) error {
+				if len(allowed) == 0 {
+					return nil
+				}
+				for _, val := range allowed {
+					if value == val {
+						return nil
+					}
+				}
+				return SubsetValidationError{key, value}
+			})
+		}
+	}
+
+	// TrimElements makes getters returning slices, i.e. Strings, trim whitespace from each element after splitting,
+	// and discard elements that are empty after trimming. Element validators, i.e. ValidateSubsetOf, are applied to
+	// the remaining elements. Other getters ignore it.
+	TrimElements = func() Option { return func(o *option) { o.trimElements = true } }
+
+	// Transform normalizes a parameter before it's validated and converted, i.e. Transform(strings.TrimSpace) makes
+	// Int accept " 3306 ". Transforms are applied to the resolved value, including defaults, in the order given.
+	Transform = func(fn func(string) string) Option {
+		return func(o *option) {
+			o.transforms = append(o.transforms, func(key, value string) (string, error) { return fn(value), nil })
+		}
+	}
+
+	// ConvertUnit converts a parameter with a unit suffix into a number in the canonical unit, by multiplying the
+	// number with the factor for the suffix, i.e. "5km" becomes "5000" with the factors {"km": 1000, "m": 1} and
+	// the canonical unit "m". The longest matching suffix is used, and whitespace between the number and the suffix
+	// is allowed. Numbers without a suffix are taken to be in the canonical unit already.
+	// Conversion happens before validation, so validation options apply to the converted number. This is meant for
+	// numeric getters, such as Int and Float. A ConversionError is returned for unknown suffixes and invalid numbers.
+	ConvertUnit = func(factors map[string]float64, canonical string) Option {
+		return func(o *option) {
+			o.transforms = append(o.transforms, func(key, value string) (string, error) {
+				var suffix string
+				for unit := range factors {
+					if strings.HasSuffix(value, unit) && len(unit) > len(suffix) {
+						suffix = unit
+					}
+				}
+				factor := 1.0
+				if suffix != "" {
+					factor = factors[suffix]
+				}
+				num, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, suffix)), 64)
+				if err != nil {
+					return "", ConversionError{key, value, fmt.Sprintf("unit %q", canonical)}
+				}
+				return strconv.FormatFloat(num*factor, 'f', -1, 64), nil
+			})
+		}
+	}
+
+	// ValidateWithinRoot validates a parameter as a file path that doesn't escape the given root directory, i.e.
+	// via "../". Relative paths are resolved relative to root, while absolute paths must be located within root.
+	// Paths are cleaned with filepath.Clean before they're checked, but symbolic links are not resolved.
+	// A PathValidationError is returned if the path is outside root.
+	ValidateWithinRoot = func(root string) Option {
+		return func(o *option) {
+			o.validate("withinroot", func(key, value string) error {
+				path := filepath.Clean(value)
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(root, path)
+				}
+				rel, err := filepath.Rel(filepath.Clean(root), path)
+				if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					return PathValidationError(key)
+				}
+				return nil
+			})
+		}
+	}
+
+	// uuidRegExp is the regular expression used to validate UUIDs in canonical form.
+	uuidRegExp *regexp.Regexp
+
+	// ValidateUUID validates a parameter as a UUID in the canonical 8-4-4-4-12 hexadecimal form, i.e.
+	// "123e4567-e89b-12d3-a456-426614174000". Matching is case-insensitive, and the UUID may be enclosed in braces.
+	// A UUIDValidationError is returned if the parameter is not a UUID.
+	ValidateUUID = func() Option {
+		return func(o *option) {
+			o.validate("uuid", func(key, value string) error {
+				if _, ok := normalizeUUID(value); !ok {
+					return UUIDValidationError(key)
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidateURL validates a parameter as an absolute URL with a scheme, i.e. "https://example.com/api", as parsed
+	// by url.ParseRequestURI. A URLValidationError is returned if the parameter is not an absolute URL.
+	ValidateURL = func() Option {
+		return func(o *option) {
+			o.validate("url", func(key, value string) error {
+				if _, ok := parseAbsoluteURL(value); !ok {
+					return URLValidationError(key)
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidateIP validates a parameter as an IPv4 or IPv6 address, as parsed by net.ParseIP, i.e. "192.168.0.1" or
+	// "::1". An IPValidationError is returned if the parameter is not an IP address.
+	ValidateIP = func() Option {
+		return func(o *option) {
+			o.validate("ip", func(key, value string) error {
+				if net.ParseIP(value) == nil {
+					return IPValidationError(key)
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidatePort validates a parameter as a port number between 1 and 65535. If allowZero is true, 0 is accepted
+	// as well, which is commonly used to let the operating system pick any available port.
+	// A PortValidationError is returned if the parameter is not a valid port number.
+	ValidatePort = func(allowZero bool) Option {
+		return func(o *option) {
+			o.validate("port", func(key, value string) error {
+				port, err := strconv.ParseUint(value, 10, 16)
+				if err != nil || port == 0 && !allowZero {
+					return PortValidationError(key)
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidatePowerOfTwo validates a parameter as a positive power of two (1, 2, 4, 8, ...), which is a common
+	// requirement for buffer and cache sizes. A ConversionError is returned if the parameter isn't an integer,
+	// and a PowerOfTwoValidationError is returned if it's not a positive power of two.
+	ValidatePowerOfTwo = func() Option {
+		return func(o *option) {
+			o.validate("poweroftwo", func(key, value string) error {
+				i, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return ConversionError{key, value, "int64"}
+				}
+				if i <= 0 || i&(i-1) != 0 {
+					return PowerOfTwoValidationError(key)
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidatePositive validates a parameter as a number greater than zero, i.e. for counts, sizes and timeouts.
+	// A ConversionError is returned if the parameter isn't a number, and a SignValidationError is returned if it's
+	// zero or negative.
+	ValidatePositive = func() Option {
+		return func(o *option) {
+			o.validate("positive", func(key, value string) error {
+				f, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return ConversionError{key, value, "float64"}
+				}
+				if !(f > 0) {
+					return SignValidationError{key, value, "positive"}
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidateNonNegative validates a parameter as a number greater than or equal to zero.
+	// A ConversionError is returned if the parameter isn't a number, and a SignValidationError is returned if it's
+	// negative.
+	ValidateNonNegative = func() Option {
+		return func(o *option) {
+			o.validate("nonnegative", func(key, value string) error {
+				f, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return ConversionError{key, value, "float64"}
+				}
+				if !(f >= 0) {
+					return SignValidationError{key, value, "non-negative"}
+				}
+				return nil
+			})
+		}
+	}
+
+	// ValidateLength validates that the number of characters (runes) in a parameter is between min and max,
+	// inclusive, i.e. for user names and tokens. A max of zero or less means there is no upper bound.
+	// A LengthValidationError is returned if the parameter is too short or too long.
+	ValidateLength = func(min, max int) Option {
+		return func(o *option) {
+			o.validate("length", func(key, value string) error {
+				n := utf8.RuneCountInString(value)
+				if n < min || (max > 0 && n > max) {
+					return LengthValidationError{key, n, min, max}
+				}
+				return nil
+			})
 		}
 	}
 )
@@ -63,17 +392,237 @@ type Option func(*option)
 
 // option is the internal representation of the set of options for a parameter.
 type option struct {
-	defaultValue   string
-	validateRegExp *regexp.Regexp
-	validateFunc   func(key, value string) error
-	require        bool
+	defaultValue    string
+	defaultFunc     func(key string) (string, error)
+	defaults        Params
+	transforms      []func(key, value string) (string, error)
+	validators      []validator
+	elements        []validator // Validators applied to each element by getters returning slices.
+	require         bool
+	nonZero         bool
+	trimElements    bool              // Trim and drop empty elements, only used by getters returning slices.
+	fallback        *string           // Name of the fallback section, only used by pool-level getters.
+	sectionDefaults map[string]string // Defaults by section name, only used by pool-level getters.
+
+	// trace is called with the outcome of each validator, if set.
+	trace func(name string, err error)
+}
+
+// validator represents a single validation option, identified by name.
+type validator struct {
+	name string
+	fn   func(key, value string) error
+}
+
+// validate adds a validator with the given name and validation function.
+// Validators run in the order they were added.
+func (o *option) validate(name string, fn func(key, value string) error) {
+	o.validators = append(o.validators, validator{name, fn})
+}
+
+// activeValidators returns the given validators that are applied, in order. ValidateRegExp, ValidateIntegral,
+// ValidateFunc and ValidateEnum keep the precedence they've always had among themselves: only one of them is
+// applied, which is the last regular expression given, or otherwise the last function or enum given. All other
+// validators are applied.
+func activeValidators(validators []validator) []validator {
+	legacy := -1
+	for i, v := range validators {
+		switch v.name {
+		case "regexp", "integral":
+			legacy = i
+		case "func", "enum":
+			if legacy < 0 || (validators[legacy].name != "regexp" && validators[legacy].name != "integral") {
+				legacy = i
+			}
+		}
+	}
+
+	res := make([]validator, 0, len(validators))
+	for i, v := range validators {
+		switch v.name {
+		case "regexp", "integral", "func", "enum":
+			if i != legacy {
+				continue
+			}
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+// validateElement adds a validator with the given name and validation function, which is applied to each element
+// of a list parameter after splitting. Element validators run in the order they were added.
+func (o *option) validateElement(name string, fn func(key, value string) error) {
+	o.elements = append(o.elements, validator{name, fn})
+}
+
+// validateElements applies the element validators of the given options to each of the given elements, stopping at
+// the first failure.
+func validateElements(key string, elements []string, options ...Option) error {
+	opt := newOption(options...)
+	for _, elem := range elements {
+		for _, v := range opt.elements {
+			err := v.fn(key, elem)
+			if opt.trace != nil {
+				opt.trace(v.name, err)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateRegExp returns a validation function that matches values against the given regular expression.
+func validateRegExp(regex *regexp.Regexp) func(key, value string) error {
+	return func(key, value string) error {
+		if !regex.MatchString(value) {
+			return RegExpValidationError(key)
+		}
+		return nil
+	}
+}
+
+// resolvedOption returns an Option which applies the already resolved options in opt, so that they don't have to
+// be resolved again every time they're used. Validators and transforms are added before those of any options
+// applied afterwards, while the remaining settings can be overridden by options applied afterwards.
+func resolvedOption(opt option) Option {
+	return func(o *option) {
+		if opt.defaultValue != "" {
+			o.defaultValue = opt.defaultValue
+		}
+		if opt.defaultFunc != nil {
+			o.defaultFunc = opt.defaultFunc
+		}
+		if opt.defaults != nil {
+			o.defaults = opt.defaults
+		}
+		if opt.fallback != nil {
+			o.fallback = opt.fallback
+		}
+		if opt.sectionDefaults != nil {
+			o.sectionDefaults = opt.sectionDefaults
+		}
+		if opt.trace != nil {
+			o.trace = opt.trace
+		}
+		o.transforms = append(o.transforms, opt.transforms...)
+		o.validators = append(o.validators, opt.validators...)
+		o.elements = append(o.elements, opt.elements...)
+		o.require = o.require || opt.require
+		o.nonZero = o.nonZero || opt.nonZero
+		o.trimElements = o.trimElements || opt.trimElements
+	}
+}
+
+// newOption returns the internal representation of the given options.
+func newOption(options ...Option) option {
+	var opt option
+	for _, o := range options {
+		o(&opt)
+	}
+	return opt
 }
 
 // Pool represents a pool of configuration data, divided into named sections.
+// The parameters are stored as an immutable snapshot which is replaced as a whole whenever the pool is modified.
+// This allows reads to proceed without locking, while writes are serialized and never block readers.
 type Pool struct {
-	mu sync.Mutex // Protects access to the fields below.
+	params atomic.Value // The current snapshot, of type map[string]map[string]string. Never modified in place.
 
-	params map[string]map[string]string
+	traceValidation uint32       // Accessed atomically, non-zero if validation tracing is enabled.
+	trackAccesses   uint32       // Accessed atomically, non-zero if access tracking is enabled.
+	metricsHook     atomic.Value // The hook set via SetMetricsHook, of type func(op, section, key string, d time.Duration).
+	keyOptions      atomic.Value // The options set via SetKeyOptions, of type map[string]map[string]Option.
+	fallback        atomic.Value // The section set via SetFallbackSection, of type string.
+
+	mu sync.Mutex // Serializes writers, and protects access to the fields below.
+
+	order    []string // Section names in insertion order.
+	traces   map[string]map[string][]string
+	accesses map[string]map[string]int // Nil unless access tracking is enabled.
+
+	secretResolver func(ref string) (string, error)
+
+	sources       map[string]map[string]string            // Nil unless provenance is enabled.
+	snapshots     map[string]map[string]map[string]string // Saved snapshots by label.
+	snapshotOrder []string                                // Snapshot labels, oldest first.
+}
+
+// newPool returns a new configuration pool using the given parameters as its snapshot.
+// The caller must not modify params afterwards.
+func newPool(params map[string]map[string]string) *Pool {
+	p := &Pool{}
+	p.store(params)
+	return p
+}
+
+// load returns the current snapshot of the pool's parameters. The snapshot must not be modified.
+func (p *Pool) load() map[string]map[string]string {
+	params, _ := p.params.Load().(map[string]map[string]string)
+	return params
+}
+
+// store replaces the current snapshot with the given parameters. The caller must hold the lock,
+// and must not modify params afterwards.
+func (p *Pool) store(params map[string]map[string]string) {
+	p.params.Store(params)
+}
+
+// withSection returns a shallow copy of the given parameters in which the section of the given name has been
+// replaced with a copy that the caller is free to modify. The copied section is returned as well.
+// The section must exist.
+func withSection(params map[string]map[string]string, section string) (map[string]map[string]string, map[string]string) {
+	res := make(map[string]map[string]string, len(params))
+	for sec, keys := range params {
+		res[sec] = keys
+	}
+	sec := make(map[string]string, len(params[section]))
+	for key, val := range params[section] {
+		sec[key] = val
+	}
+	res[section] = sec
+	return res, sec
+}
+
+// resolvedValue represents a value resolved from a snapshot of the pool without holding the lock, i.e. a secret
+// fetched by ResolveSecrets, which is applied by applyResolved.
+type resolvedValue struct {
+	section, key string // The key whose value was resolved.
+	from         string // The value the key held when it was resolved.
+	to           string // The resolved value.
+	toKey        string // The key to set to the resolved value, if not key itself. Key is then removed.
+}
+
+// applyResolved applies the given resolved values to the pool at once. Values whose keys no longer hold the value
+// they were resolved from, i.e. because they were changed by Set or Merge while resolving, are skipped, so that
+// such changes aren't lost. The recorded sources of the keys that are set or removed are removed.
+func (p *Pool) applyResolved(values []resolvedValue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	copied := make(map[string]map[string]string)
+	for _, v := range values {
+		if val, ok := params[v.section][v.key]; !ok || val != v.from {
+			continue
+		}
+		sec, ok := copied[v.section]
+		if !ok {
+			params, sec = withSection(params, v.section)
+			copied[v.section] = sec
+		}
+		key := v.key
+		if v.toKey != "" && v.toKey != v.key {
+			delete(sec, v.key)
+			p.recordSource(v.section, v.key, "")
+			key = v.toKey
+		}
+		sec[key] = v.to
+		p.recordSource(v.section, key, "")
+	}
+	p.store(params)
 }
 
 // Params represents a subset of a configuration pool.
@@ -105,28 +654,34 @@ func New(params map[string]map[string]string) *Pool {
 // Raw returns the entire configuration pool as-is.
 // Modifying the return value will not affect the configuration pool.
 func (p *Pool) Raw() map[string]map[string]string {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	params := p.load()
 
 	myPool := make(map[string]map[string]string)
-	for name, section := range p.params {
+	for name, section := range params {
 		myPool[name] = make(map[string]string)
 		for key, val := range section {
 			myPool[name][key] = val
 		}
 	}
 
-	return p.params
+	return myPool
 }
 
 // Params returns the section identified by the given name.
 // The parameter ok is false if the section does not exist.
+// If a fallback section is set via SetFallbackSection, keys that are missing from the section are copied from the
+// fallback section. Keys that exist in the section are never overridden, even if they're empty. A missing section
+// is treated like a section without keys in that case, so ok is only false if the fallback section doesn't exist
+// either.
 func (p *Pool) Params(name string) (section Params, ok bool) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	params, ok := p.params[name]
-	if !ok {
+	all := p.load()
+	params, ok := all[name]
+	var fallback map[string]string
+	var hasFallback bool
+	if fallbackName := p.fallbackSection(); fallbackName != "" {
+		fallback, hasFallback = all[fallbackName]
+	}
+	if !ok && !hasFallback {
 		return
 	}
 
@@ -134,8 +689,15 @@ func (p *Pool) Params(name string) (section Params, ok bool) {
 	for key, val := range params {
 		section[key] = val
 	}
+	if hasFallback {
+		for key, val := range fallback {
+			if _, ok := section[key]; !ok {
+				section[key] = val
+			}
+		}
+	}
 
-	return
+	return section, true
 }
 
 // Merge stores the given map of configuration parameters, overriding (by default)
@@ -145,127 +707,1139 @@ func (p *Pool) Params(name string) (section Params, ok bool) {
 // - Report: the merge is aborted with an error on the first conflicting key name
 // The default strategy is Report.
 func (p *Pool) Merge(params map[string]map[string]string, strategy Strategy) error {
+	return p.MergeFrom("", params, strategy)
+}
+
+// MergeFrom works like Merge, and additionally records the given source, i.e. "defaults" or "env", as the source
+// of every key that is set by the merge, if provenance is enabled. See EnableProvenance.
+func (p *Pool) MergeFrom(source string, params map[string]map[string]string, strategy Strategy) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	res, err := merge(p.params, params, strategy)
-	if err != nil {
-		return err
+	return p.mergeLocked(source, params, strategy)
+}
+
+// MergeProtecting works like Merge, except that the keys listed in protected, by section, are never overwritten
+// regardless of the strategy, i.e. runtime-managed keys such as a generated node ID that must survive reloading
+// a configuration file. Protected keys that don't exist in the pool are set by the merge as usual.
+func (p *Pool) MergeProtecting(params map[string]map[string]string, protected map[string][]string, strategy Strategy) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := p.load()
+	filtered := make(map[string]map[string]string, len(params))
+	for sec, keys := range params {
+		filtered[sec] = make(map[string]string, len(keys))
+		for key, val := range keys {
+			filtered[sec][key] = val
+		}
+		for _, key := range protected[sec] {
+			if _, ok := current[sec][key]; ok {
+				delete(filtered[sec], key)
+			}
+		}
 	}
-	p.params = res
-	return nil
+	return p.mergeLocked("", filtered, strategy)
 }
 
-// Get returns the value for the given key in the given section.
-// The return value ok will be true if the key exists, and false otherwise.
-// Get provides none of the helper methods provided by Params and should generally but be used to access
-// keys from the configuration pool. However, Get may be useful for other reasons.
-func (p *Pool) Get(section, key string) (value string, ok bool) {
+// MergeWith works like Merge, except that conflicts are resolved by the given resolver instead of a strategy.
+// The resolver is called for every key that exists both in the pool and in params, with the existing and the
+// incoming value, and the value it returns is stored, i.e. to take the longer of two values or to combine lists.
+// The resolver is called in the order of sections and keys, sorted alphabetically. If it returns an error, the
+// merge is aborted, the pool is left unmodified and the error is returned along with the section and key.
+func (p *Pool) MergeWith(params map[string]map[string]string, resolver func(section, key, existing, incoming string) (string, error)) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	sec, ok := p.params[section]
-	if !ok {
-		return
+	current := p.load()
+	resolved := copyParams(params)
+	sections := make([]string, 0, len(params))
+	for sec := range params {
+		sections = append(sections, sec)
 	}
-	value, ok = sec[key]
-	return
+	sort.Strings(sections)
+
+	for _, sec := range sections {
+		keys := make([]string, 0, len(params[sec]))
+		for key := range params[sec] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			existing, ok := current[sec][key]
+			if !ok {
+				continue
+			}
+			val, err := resolver(sec, key, existing, params[sec][key])
+			if err != nil {
+				return fmt.Errorf("section %q, key %q: %w", sec, key, err)
+			}
+			resolved[sec][key] = val
+		}
+	}
+	return p.mergeLocked("", resolved, Overwrite)
 }
 
-// Set adds the given key and value pair to the section of the given name.
-// If the section doesn't exist, a NoSectionError is returned. If value is an empty string, then
-// the key will be set to an empty string as well (which is not the same as unsetting a key).
-func (p *Pool) Set(section, key, value string) error {
+// MergeReport works like Merge with the Report strategy, except that all conflicting keys are reported rather than
+// just the first one, which is useful for validating large override files. If any keys already exist in the pool,
+// a MergeConflictError listing all of them is returned, and the pool is left unmodified.
+func (p *Pool) MergeReport(params map[string]map[string]string) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	sec, ok := p.params[section]
-	if !ok {
-		return NoSectionError(section)
+	current := p.load()
+	conflicts := make([]Conflict, 0)
+	for sec, keys := range params {
+		for key, val := range keys {
+			if existing, ok := current[sec][key]; ok {
+				conflicts = append(conflicts, Conflict{Section: sec, Key: key, Mine: existing, Theirs: val})
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Slice(conflicts, func(i, j int) bool {
+			if conflicts[i].Section != conflicts[j].Section {
+				return conflicts[i].Section < conflicts[j].Section
+			}
+			return conflicts[i].Key < conflicts[j].Key
+		})
+		return MergeConflictError{conflicts}
+	}
+	return p.mergeLocked("", params, Report)
+}
+
+// mergeLocked merges the given parameters into the pool, see MergeFrom. The caller must hold the lock.
+func (p *Pool) mergeLocked(source string, params map[string]map[string]string, strategy Strategy) error {
+	// The given parameters are copied, since merge may return them as-is and they become part of the snapshot.
+	current := p.load()
+	res, err := merge(current, copyParams(params), strategy)
+	if err != nil {
+		return err
+	}
+	p.order = appendNewSections(p.order, current, params)
+	p.store(res)
+
+	for sec, keys := range params {
+		for key := range keys {
+			if _, ok := current[sec][key]; ok && strategy == Keep {
+				continue
+			}
+			p.recordSource(sec, key, source)
+		}
 	}
-	sec[key] = value
 	return nil
 }
 
-// Unset attempts to remove the given key from the given section.
-// You can provide an empty string for the key to remove the entire section.
-// It returns true if the key/section was removed, otherwise it returns false.
-func (p *Pool) Unset(section, key string) bool {
+// MergePreview returns a new configuration pool with the result of merging the given parameters into the pool
+// that MergePreview is called from, using the given strategy, without modifying it. This makes it possible to
+// inspect the outcome of a merge, i.e. by comparing the preview to the current pool, before committing to it.
+// Any error that Merge would return, such as a conflict with the Report strategy, is returned as well.
+func (p *Pool) MergePreview(params map[string]map[string]string, strategy Strategy) (*Pool, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	sec, ok := p.params[section]
-	if !ok {
-		return false
+	current := p.load()
+	res, err := merge(copyParams(current), copyParams(params), strategy)
+	if err != nil {
+		return nil, err
+	}
+	preview := newPool(res)
+	preview.order = appendNewSections(append([]string{}, p.order...), current, params)
+	return preview, nil
+}
+
+// appendNewSections appends the names of the sections in params that are not present in existing to the given
+// order. Since maps are unordered, sections that are new are appended in alphabetical order.
+func appendNewSections(order []string, existing, params map[string]map[string]string) []string {
+	added := make([]string, 0)
+	for sec := range params {
+		if _, ok := existing[sec]; !ok {
+			added = append(added, sec)
+		}
+	}
+	sort.Strings(added)
+	return append(order, added...)
+}
+
+// SectionNames returns the names of all sections in the pool, sorted alphabetically. See OrderedSections for the
+// names in the order the sections were added.
+func (p *Pool) SectionNames() []string {
+	params := p.load()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OrderedSections returns the names of all sections in the order they were added to the pool, which is useful
+// when the order of declaration is meaningful. Since maps are unordered, sections added by the same call to New
+// or Merge are ordered alphabetically among themselves, except for pools loaded via LoadINI, whose sections are
+// ordered as in the INI. Sections of pools derived from other pools, i.e. via Rebase, are ordered alphabetically.
+func (p *Pool) OrderedSections() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	sections := make([]string, 0, len(params))
+	seen := make(map[string]bool, len(params))
+	for _, sec := range p.order {
+		if _, ok := params[sec]; ok && !seen[sec] {
+			sections = append(sections, sec)
+			seen[sec] = true
+		}
+	}
+
+	rest := make([]string, 0)
+	for sec := range params {
+		if !seen[sec] {
+			rest = append(rest, sec)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(sections, rest...)
+}
+
+// Rebase returns a new configuration pool with the contents of defaults overlaid with the contents of the pool
+// that Rebase is called from. Keys present in both pools retain the values of the receiver, while keys that are
+// only present in defaults are added. This is useful when shipping a new set of defaults without losing any
+// values that were explicitly configured. Neither pool is modified.
+func (p *Pool) Rebase(defaults *Pool) *Pool {
+	res, _ := merge(defaults.copyParams(), p.copyParams(), Overwrite) // There's no error for Overwrite strategy.
+	return newPool(res)
+}
+
+// Clone returns a deep copy of the pool, with the sections in the same order. Modifying the clone will not affect
+// the pool that Clone is called from, and vice versa. Settings such as hooks, key options and tracking are not
+// copied, so the clone starts out with the defaults.
+func (p *Pool) Clone() *Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clone := newPool(p.copyParams())
+	clone.order = append([]string(nil), p.order...)
+	return clone
+}
+
+// ApplyOverlay returns a new configuration pool where environment-specific overlay sections have been merged into
+// their base sections. An overlay section is named after its base section, followed by sep and a selector, i.e.
+// "db@prod" is the overlay of "db" for the selector "prod" when sep is "@". Only sections whose base section exists
+// are considered overlays, so that other sections containing sep, i.e. "admin@example.com", are left as they are.
+// Overlays matching the given selector are merged into their base sections using the Overwrite strategy.
+// All overlay sections, whether they match the selector or not, are left out of the returned pool.
+// The pool that ApplyOverlay is called from is not modified. If sep is empty, an unaltered copy is returned.
+func (p *Pool) ApplyOverlay(selector, sep string) *Pool {
+	params := p.copyParams()
+	if sep == "" {
+		return newPool(params)
+	}
+
+	res := make(map[string]map[string]string, len(params))
+	overlays := make(map[string]map[string]map[string]string)
+	for sec, keys := range params {
+		i := strings.LastIndex(sec, sep)
+		if i < 0 {
+			res[sec] = keys
+			continue
+		}
+		if _, ok := params[sec[:i]]; !ok {
+			res[sec] = keys // Not an overlay, since there's no base section.
+			continue
+		}
+		if sec[i+len(sep):] == selector {
+			overlays[sec[:i]] = map[string]map[string]string{sec[:i]: keys}
+		}
+	}
+
+	for _, overlay := range overlays {
+		res, _ = merge(res, overlay, Overwrite) // There's no error for Overwrite strategy.
+	}
+
+	return newPool(res)
+}
+
+// copyParams returns a deep copy of the pool's parameters.
+func (p *Pool) copyParams() map[string]map[string]string {
+	return copyParams(p.load())
+}
+
+// copyParams returns a deep copy of the given parameters.
+func copyParams(params map[string]map[string]string) map[string]map[string]string {
+	res := make(map[string]map[string]string, len(params))
+	for sec, keys := range params {
+		res[sec] = make(map[string]string, len(keys))
+		for key, val := range keys {
+			res[sec][key] = val
+		}
+	}
+	return res
+}
+
+// Get returns the value for the given key in the given section.
+// The return value ok will be true if the key exists, and false otherwise.
+// Get provides none of the helper methods provided by Params and should generally but be used to access
+// keys from the configuration pool. However, Get may be useful for other reasons.
+// If a fallback section is set via SetFallbackSection, the key is looked up in the fallback section if it doesn't
+// exist in the given section.
+func (p *Pool) Get(section, key string) (value string, ok bool) {
+	p.recordAccess(section, key)
+	all := p.load()
+	if value, ok = all[section][key]; ok {
+		return
+	}
+	if fallback := p.fallbackSection(); fallback != "" {
+		p.recordAccess(fallback, key)
+		value, ok = all[fallback][key]
+	}
+	return
+}
+
+// GetOr returns the value for the given key in the given section, or fallback if the section or key doesn't exist,
+// or if the value is empty. It's a shorthand for Get when no options are needed.
+func (p *Pool) GetOr(section, key, fallback string) string {
+	if value, _ := p.Get(section, key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Set adds the given key and value pair to the section of the given name.
+// If the section doesn't exist, a NoSectionError is returned. If value is an empty string, then
+// the key will be set to an empty string as well (which is not the same as unsetting a key).
+func (p *Pool) Set(section, key, value string) error {
+	return p.SetFrom("", section, key, value)
+}
+
+// SetFrom works like Set, and additionally records the given source as the source of the key, if provenance is
+// enabled. See EnableProvenance.
+func (p *Pool) SetFrom(source, section, key, value string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	if _, ok := params[section]; !ok {
+		return NoSectionError(section)
+	}
+	params, sec := withSection(params, section)
+	sec[key] = value
+	p.store(params)
+	p.recordSource(section, key, source)
+	return nil
+}
+
+// Unset attempts to remove the given key from the given section.
+// You can provide an empty string for the key to remove the entire section.
+// It returns true if the key/section was removed, otherwise it returns false.
+func (p *Pool) Unset(section, key string) bool {
+	_, ok := p.Remove(section, key)
+	return ok
+}
+
+// Remove works like Unset, and additionally returns the value of the removed key, i.e. for logging a secret that
+// is being rotated. The value is empty if nothing was removed, or if an entire section was removed.
+func (p *Pool) Remove(section, key string) (value string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	sec, ok := params[section]
+	if !ok {
+		return "", false
+	}
+
+	// Removing the section.
+	if key == "" {
+		res := make(map[string]map[string]string, len(params))
+		for name, keys := range params {
+			if name != section {
+				res[name] = keys
+			}
+		}
+		p.store(res)
+		if p.sources != nil {
+			delete(p.sources, section)
+		}
+		for i, sec := range p.order {
+			if sec == section {
+				p.order = append(p.order[:i:i], p.order[i+1:]...)
+				break
+			}
+		}
+		return "", true
+	}
+
+	// Removing the key.
+	value, ok = sec[key]
+	if ok {
+		params, sec = withSection(params, section)
+		delete(sec, key)
+		p.store(params)
+		p.recordSource(section, key, "")
+	}
+	return value, ok
+}
+
+// EmptySections returns the names of the sections that contain no keys, sorted alphabetically.
+func (p *Pool) EmptySections() []string {
+	names := make([]string, 0)
+	for name, keys := range p.load() {
+		if len(keys) == 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Prune removes all sections that contain no keys, i.e. sections left empty after unsetting their keys, and
+// returns the number of sections removed. Sections that are intentionally empty are removed as well, so callers
+// that rely on their presence shouldn't call Prune.
+func (p *Pool) Prune() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	res := make(map[string]map[string]string, len(params))
+	for name, keys := range params {
+		if len(keys) > 0 {
+			res[name] = keys
+		}
+	}
+	removed := len(params) - len(res)
+	if removed == 0 {
+		return 0
+	}
+
+	p.store(res)
+	order := make([]string, 0, len(res))
+	for _, name := range p.order {
+		if _, ok := res[name]; ok {
+			order = append(order, name)
+		}
+	}
+	p.order = order
+	for name := range p.sources {
+		if _, ok := res[name]; !ok {
+			delete(p.sources, name)
+		}
+	}
+	return removed
+}
+
+// RenameSection moves the keys of the section oldName to a new section newName, i.e. to normalize "DB" into
+// "database". The section keeps its position in OrderedSections. Renaming a section to its own name does nothing.
+// A NoSectionError is returned if oldName doesn't exist, and a SectionExistsError is returned if newName exists.
+func (p *Pool) RenameSection(oldName, newName string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	keys, ok := params[oldName]
+	if !ok {
+		return NoSectionError(oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, ok = params[newName]; ok {
+		return SectionExistsError(newName)
+	}
+
+	res := make(map[string]map[string]string, len(params))
+	for name, keys := range params {
+		if name != oldName {
+			res[name] = keys
+		}
+	}
+	res[newName] = keys
+	p.store(res)
+
+	order := make([]string, len(p.order))
+	for i, sec := range p.order {
+		if sec == oldName {
+			sec = newName
+		}
+		order[i] = sec
+	}
+	p.order = order
+	if p.sources != nil {
+		if sources, ok := p.sources[oldName]; ok {
+			p.sources[newName] = sources
+			delete(p.sources, oldName)
+		}
+	}
+	return nil
+}
+
+// CompareAndSwap sets the given key in the given section to new, but only if its current value equals old.
+// It returns true if the value was swapped. A key that doesn't exist is treated as having an empty value, so
+// passing an empty string for old will add the key if it's missing. The section must exist, otherwise false
+// is returned. Since the comparison and the update happen under the same lock, concurrent callers can
+// coordinate updates of a single key without further synchronization.
+func (p *Pool) CompareAndSwap(section, key, old, new string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	sec, ok := params[section]
+	if !ok || sec[key] != old {
+		return false
+	}
+	params, sec = withSection(params, section)
+	sec[key] = new
+	p.store(params)
+	p.recordSource(section, key, "")
+	return true
+}
+
+// Compare returns the sections and parameters from the given pool that doesn't
+// already exist in the pool that Compare is called from.
+// Two pools p1 and p2 are identical if, and only if
+// len(p1.Compare(p2)) == 0 && len(p2.Compare(p1)) == 0
+func (p *Pool) Compare(pool *Pool) map[string]map[string]string {
+	return diff(pool.load(), p.load())
+}
+
+// CompareSections works like Compare, but only compares the sections with the given names, which avoids
+// computing the differences for the entire pool when only a few sections are of interest.
+func (p *Pool) CompareSections(pool *Pool, sections ...string) map[string]map[string]string {
+	first, second := pool.load(), p.load()
+	scopedFirst := make(map[string]map[string]string, len(sections))
+	scopedSecond := make(map[string]map[string]string, len(sections))
+	for _, sec := range sections {
+		if params, ok := first[sec]; ok {
+			scopedFirst[sec] = params
+		}
+		if params, ok := second[sec]; ok {
+			scopedSecond[sec] = params
+		}
+	}
+	return diff(scopedFirst, scopedSecond)
+}
+
+// RequireSections returns an error if any of the sections with the given names does not exist.
+// The returned error is a MultiError containing a NoSectionError for each missing section, in the order given.
+// This makes it possible to check for the presence of all sections required by an application at startup.
+func (p *Pool) RequireSections(names ...string) error {
+	params := p.load()
+	errs := make([]error, 0)
+	for _, name := range names {
+		if _, ok := params[name]; !ok {
+			errs = append(errs, NoSectionError(name))
+		}
+	}
+	return multiError(errs)
+}
+
+// ValidateNoUnresolved returns an error if any value in the configuration pool contains an unresolved interpolation
+// token, i.e. "${hsot}". This is useful as a safety net after expanding variables, since misspelled tokens would
+// otherwise pass through unnoticed. The returned error is a MultiError containing an UnresolvedTokenError for each
+// token, sorted by section and key.
+func (p *Pool) ValidateNoUnresolved() error {
+	errs := make([]error, 0)
+	for _, entry := range p.Entries() {
+		for _, token := range unresolvedRegExp.FindAllString(entry.Value, -1) {
+			errs = append(errs, UnresolvedTokenError{entry.Section, entry.Key, token})
+		}
+	}
+	return multiError(errs)
+}
+
+// ExpandEnv replaces references to environment variables in every value with the values of the variables, using
+// os.Expand, i.e. "${DB_PASSWORD}" or "$DB_PASSWORD". References to unset or empty variables are replaced with
+// empty strings, unless a default is given in the form "${DB_PORT:-3306}". Since expansion modifies the pool,
+// it's meant to be called once after the pool has been created; calling it again would expand "$" characters
+// produced by the first expansion. Use ValidateNoUnresolved afterwards to catch malformed references.
+func (p *Pool) ExpandEnv() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := p.load()
+	copied := make(map[string]map[string]string)
+	for sec, keys := range params {
+		for key, val := range keys {
+			expanded := os.Expand(val, expandEnvVar)
+			if expanded == val {
+				continue
+			}
+			res, ok := copied[sec]
+			if !ok {
+				params, res = withSection(params, sec)
+				copied[sec] = res
+			}
+			res[key] = expanded
+			p.recordSource(sec, key, "")
+		}
+	}
+	p.store(params)
+}
+
+// expandEnvVar returns the value of the environment variable with the given name, which may be followed by ":-"
+// and a default value that is returned if the variable is unset or empty.
+func expandEnvVar(name string) string {
+	if i := strings.Index(name, ":-"); i >= 0 {
+		if val := os.Getenv(name[:i]); val != "" {
+			return val
+		}
+		return name[i+2:]
+	}
+	return os.Getenv(name)
+}
+
+// ForEachKey calls fn for every key in the configuration pool, sorted by section and then by key.
+// Unlike the validation options, which stop at the first failure, all keys are visited, and the returned error is a
+// MultiError containing the errors returned by fn, in the order they occurred. This is useful for validations that
+// involve I/O, such as resolving host names or checking for the existence of files.
+// Since fn is called with a snapshot of the pool, it may safely call methods on the pool, including ones that
+// modify it, but such modifications won't be visited.
+func (p *Pool) ForEachKey(fn func(section, key, value string) error) error {
+	errs := make([]error, 0)
+	for _, entry := range p.Entries() {
+		if err := fn(entry.Section, entry.Key, entry.Value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return multiError(errs)
+}
+
+// Each calls fn for every key in the configuration pool, sorted by section and then by key, and stops as soon as fn
+// returns false. No lock is held while iterating; instead fn is called with the values of a single snapshot of the
+// pool, so it sees a consistent view even if the pool is modified concurrently, and may safely call methods on the
+// pool, including ones that modify it. Like ForEachKey, it iterates over the entries returned by Entries.
+func (p *Pool) Each(fn func(section, key, value string) bool) {
+	for _, entry := range p.Entries() {
+		if !fn(entry.Section, entry.Key, entry.Value) {
+			return
+		}
+	}
+}
+
+// RedundantKeys returns, per section, the keys whose values are identical to the values of the same keys in the
+// section defaultSection. When the default section is used as a fallback for other sections, these keys can be
+// removed without changing behavior. The default section itself is not included, and neither are sections without
+// redundant keys. An empty map is returned if the default section does not exist.
+func (p *Pool) RedundantKeys(defaultSection string) map[string]map[string]string {
+	all := p.load()
+	res := make(map[string]map[string]string)
+	defaults, ok := all[defaultSection]
+	if !ok {
+		return res
+	}
+
+	for sec, params := range all {
+		if sec == defaultSection {
+			continue
+		}
+		for key, val := range params {
+			if defVal, ok := defaults[key]; ok && defVal == val {
+				if res[sec] == nil {
+					res[sec] = make(map[string]string)
+				}
+				res[sec][key] = val
+			}
+		}
+	}
+
+	return res
+}
+
+// ValidateInSectionKeys validates that the value for the given key in the given section is the name of
+// one of the keys in the section allowSection. This is useful when the set of allowed values is itself
+// part of the configuration, i.e. a "defaultProfile" key that must name one of the keys in a "profiles" section.
+// A NoSectionError is returned if either section does not exist, a NoKeyError is returned if the key does not exist,
+// and a ReferenceError is returned if the value does not match any key in allowSection.
+func (p *Pool) ValidateInSectionKeys(section, key, allowSection string) error {
+	params := p.load()
+	sec, ok := params[section]
+	if !ok {
+		return NoSectionError(section)
+	}
+	allowed, ok := params[allowSection]
+	if !ok {
+		return NoSectionError(allowSection)
+	}
+	value, ok := sec[key]
+	if !ok {
+		return NoKeyError(key)
+	}
+	if _, ok = allowed[value]; !ok {
+		return ReferenceError{key, value, fmt.Sprintf("key in section %q", allowSection)}
+	}
+	return nil
+}
+
+// Keys returns the names of all keys in the section, sorted alphabetically. This is useful when key names aren't
+// known in advance, i.e. for iterating over feature flags.
+func (s Params) Keys() []string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RejectUnknownKeys returns an error if the section contains any keys other than the given allowed keys.
+// This catches misspelled keys which would otherwise be silently ignored. The returned error is a MultiError
+// containing an UnknownKeyError for each unknown key, sorted by key.
+func (s Params) RejectUnknownKeys(allowed ...string) error {
+	known := make(map[string]bool, len(allowed))
+	for _, key := range allowed {
+		known[key] = true
+	}
+
+	unknown := make([]string, 0)
+	for key := range s {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	errs := make([]error, 0, len(unknown))
+	for _, key := range unknown {
+		errs = append(errs, UnknownKeyError(key))
+	}
+	return multiError(errs)
+}
+
+// RequireIf returns an error if the given key is missing or empty, but only if the value of condKey equals
+// condValue, i.e. to require "tls.cert" only when "tls.enabled" is "true". If condValue is one of the tokens
+// accepted by Bool, i.e. "true" or "off", the value of condKey is compared as a boolean, so "on" and "yes" match
+// the condition value "true" as well. A missing or empty condKey never matches a non-empty condValue.
+// A ConditionalKeyError naming both keys is returned if the requirement isn't met.
+func (s Params) RequireIf(key, condKey, condValue string) error {
+	val := s[condKey]
+	match := val == condValue
+	if want, ok := parseBool(condValue); ok && val != "" {
+		got, ok := parseBool(val)
+		match = ok && got == want
+	}
+	if match && s[key] == "" {
+		return ConditionalKeyError{key, condKey, val}
+	}
+	return nil
+}
+
+// String returns the string value for the given key in the current section.
+// A NoKeyError is returned if the key is required but does not exist.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) String(key string, options ...Option) (string, error) {
+	val, ok := s[key]
+	return checkApplyOptions(key, val, ok, options...)
+}
+
+// Strings returns the string values for the given key in the given section.
+// Separator will be used to split the string into a slice.
+// A NoKeyError is returned if the key is required but does not exist.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+// Note that any validation options passed are applied to the string value *before* splitting
+// it into multiples, except for ValidateSubsetOf which is applied to each element after splitting.
+// Elements are returned verbatim, including empty ones, unless the TrimElements option is given, in which case
+// nil is returned if no elements remain.
+func (s Params) Strings(key, separator string, options ...Option) ([]string, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return nil, err
+	}
+	ss := strings.Split(val, separator)
+	if newOption(options...).trimElements {
+		trimmed := ss[:0]
+		for _, elem := range ss {
+			if elem = strings.TrimSpace(elem); elem != "" {
+				trimmed = append(trimmed, elem)
+			}
+		}
+		if len(trimmed) == 0 {
+			return nil, nil
+		}
+		ss = trimmed
+	}
+	if err = validateElements(key, ss, options...); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// HostPorts returns the value for the given key split into multiple "host:port" endpoints by separator, i.e.
+// "a:9092,b:9092". Whitespace around each endpoint is trimmed, and endpoints are normalized, i.e. leading zeros
+// are removed from ports. IPv6 hosts must be enclosed in square brackets, i.e. "[::1]:9092".
+// Nil is returned if the key doesn't exist or is empty.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError identifying the endpoint is returned if an endpoint has no host, or a port outside 1-65535.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed. As for Strings, ValidateSubsetOf is applied to each endpoint after
+// splitting, and all other validation options are applied before splitting.
+func (s Params) HostPorts(key, separator string, options ...Option) ([]string, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return nil, err
+	}
+	endpoints := strings.Split(val, separator)
+	for i, endpoint := range endpoints {
+		endpoint = strings.TrimSpace(endpoint)
+		host, port, err := net.SplitHostPort(endpoint)
+		if err != nil || host == "" {
+			return nil, ConversionError{key, endpoint, "host:port"}
+		}
+		p, err := strconv.ParseUint(port, 10, 16)
+		if err != nil || p == 0 {
+			return nil, ConversionError{key, endpoint, "host:port"}
+		}
+		endpoints[i] = net.JoinHostPort(host, strconv.FormatUint(p, 10))
+	}
+	if err = validateElements(key, endpoints, options...); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// StringMap returns the value for the given key as a map, by splitting it into pairs by pairSep and each pair into a
+// key and a value by kvSep, i.e. "a=1;b=2" with the separators ";" and "=". Whitespace around keys and values is
+// trimmed, values may contain kvSep, and later pairs override earlier pairs with the same key. Empty pairs, i.e. due
+// to a trailing pairSep as in "a=1;", are ignored.
+// An empty map is returned if the key doesn't exist or is empty.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError identifying the pair is returned if a pair doesn't contain kvSep, or if its key is empty.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed. As for Strings, validation options are applied before splitting.
+func (s Params) StringMap(key, pairSep, kvSep string, options ...Option) (map[string]string, error) {
+	val, err := s.String(key, options...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]string)
+	if val == "" {
+		return res, nil
+	}
+	for _, pair := range strings.Split(val, pairSep) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, kvSep, 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, ConversionError{key, pair, "key/value pair"}
+		}
+		res[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return res, nil
+}
+
+// Slug returns the value for the given key normalized to a slug that is safe for use in i.e. metric names, table
+// names and file names. The value is lowercased, every run of characters other than a-z and 0-9 is replaced with a
+// single hyphen, and leading and trailing hyphens are removed, i.e. "My Service (EU)" becomes "my-service-eu".
+// An empty string is returned if the key doesn't exist or is empty.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the slug is empty, i.e. because the value contains no letters or digits.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed. Validation options are applied to the value before normalization.
+func (s Params) Slug(key string, options ...Option) (string, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return "", err
+	}
+
+	var slug strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(val) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			sep = slug.Len() > 0
+			continue
+		}
+		if sep {
+			slug.WriteByte('-')
+			sep = false
+		}
+		slug.WriteRune(r)
+	}
+	if slug.Len() == 0 {
+		return "", ConversionError{key, val, "slug"}
+	}
+	return slug.String(), nil
+}
+
+// UUID returns the value for the given key as a UUID, normalized to lowercase and without braces.
+// The same forms as for ValidateUUID are accepted.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the value is not a UUID.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) UUID(key string, options ...Option) (string, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return "", err
+	}
+	uuid, ok := normalizeUUID(val)
+	if !ok {
+		return "", ConversionError{key, val, "UUID"}
+	}
+	return uuid, nil
+}
+
+// normalizeUUID returns the given UUID in lowercase and without braces. The return value ok is false if the
+// value is not a UUID.
+func normalizeUUID(value string) (uuid string, ok bool) {
+	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		value = value[1 : len(value)-1]
+	}
+	if !uuidRegExp.MatchString(value) {
+		return "", false
+	}
+	return strings.ToLower(value), true
+}
+
+// URL returns the value for the given key as an absolute URL. The same forms as for ValidateURL are accepted.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the value is not an absolute URL.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) URL(key string, options ...Option) (*url.URL, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return nil, err
+	}
+	u, ok := parseAbsoluteURL(val)
+	if !ok {
+		return nil, ConversionError{key, val, "URL"}
+	}
+	return u, nil
+}
+
+// IP returns the value for the given key as an IPv4 or IPv6 address, as parsed by net.ParseIP.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the value is not an IP address.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) IP(key string, options ...Option) (net.IP, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return nil, err
+	}
+	ip := net.ParseIP(val)
+	if ip == nil {
+		return nil, ConversionError{key, val, "IP"}
+	}
+	return ip, nil
+}
+
+// parseAbsoluteURL parses the given value with url.ParseRequestURI. The return value ok is false if the value
+// can't be parsed or if it's not an absolute URL with a scheme.
+func parseAbsoluteURL(value string) (u *url.URL, ok bool) {
+	u, err := url.ParseRequestURI(value)
+	if err != nil || !u.IsAbs() {
+		return nil, false
+	}
+	return u, true
+}
+
+// Pair returns the value for the given key split into exactly two parts by separator, i.e. "lat,lng".
+// Empty strings are returned if the key doesn't exist or is empty.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the value doesn't consist of exactly two parts.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed. Validation options are applied before splitting.
+func (s Params) Pair(key, separator string, options ...Option) (first, second string, err error) {
+	parts, err := s.parts(key, separator, 2, "Pair", options...)
+	if err != nil || parts == nil {
+		return "", "", err
 	}
+	return parts[0], parts[1], nil
+}
 
-	// Removing the section.
-	if key == "" {
-		delete(p.params, section)
-		return true
+// Triple returns the value for the given key split into exactly three parts by separator, i.e. "r,g,b".
+// Empty strings are returned if the key doesn't exist or is empty.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the value doesn't consist of exactly three parts.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed. Validation options are applied before splitting.
+func (s Params) Triple(key, separator string, options ...Option) (first, second, third string, err error) {
+	parts, err := s.parts(key, separator, 3, "Triple", options...)
+	if err != nil || parts == nil {
+		return "", "", "", err
 	}
+	return parts[0], parts[1], parts[2], nil
+}
 
-	// Removing the key.
-	_, ok = sec[key]
-	if ok {
-		delete(p.params[section], key)
+// parts returns the value for the given key split into exactly n parts by separator.
+// The datatype is used for reporting conversion errors.
+func (s Params) parts(key, separator string, n int, datatype string, options ...Option) ([]string, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return nil, err
 	}
-	return ok
+	parts := strings.Split(val, separator)
+	if len(parts) != n {
+		return nil, ConversionError{key, val, datatype}
+	}
+	return parts, nil
 }
 
-// Compare returns the sections and parameters from the given pool that doesn't
-// already exist in the pool that Compare is called from.
-// Two pools p1 and p2 are identical if, and only if
-// len(p1.Compare(p2)) == 0 && len(p2.Compare(p1)) == 0
-func (p *Pool) Compare(pool *Pool) map[string]map[string]string {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// Int attempts to convert the value for the requested key into an int.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Int(key string, options ...Option) (int, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return 0, checkNonZero(key, err, options)
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, ConversionError{key, val, "int"}
+	}
+	if i == 0 {
+		return 0, checkNonZero(key, nil, options)
+	}
+	return i, nil
+}
 
-	return diff(pool.params, p.params)
+// Int8 attempts to convert the value for the requested key into an int8.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value overflows an int8.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Int8(key string, options ...Option) (int8, error) {
+	i, err := s.signed(key, 10, 8, "int8", options...)
+	return int8(i), err
 }
 
-// String returns the string value for the given key in the current section.
+// Int16 attempts to convert the value for the requested key into an int16.
 // A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value overflows an int16.
 // A RegExpValidationError, EnumValidationError or custom error may be returned depending
 // on which validation options were passed.
-func (s Params) String(key string, options ...Option) (string, error) {
-	val, ok := s[key]
-	return checkApplyOptions(key, val, ok, options...)
+func (s Params) Int16(key string, options ...Option) (int16, error) {
+	i, err := s.signed(key, 10, 16, "int16", options...)
+	return int16(i), err
 }
 
-// Strings returns the string values for the given key in the given section.
-// Separator will be used to split the string into a slice.
+// Int32 attempts to convert the value for the requested key into an int32.
 // A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value overflows an int32.
 // A RegExpValidationError, EnumValidationError or custom error may be returned depending
 // on which validation options were passed.
-// Note that any validation options passed are applied to the string value *before* splitting
-// it into multiples.
-func (s Params) Strings(key, separator string, options ...Option) ([]string, error) {
+func (s Params) Int32(key string, options ...Option) (int32, error) {
+	i, err := s.signed(key, 10, 32, "int32", options...)
+	return int32(i), err
+}
+
+// Int64 attempts to convert the value for the requested key into an int64.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value overflows an int64.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Int64(key string, options ...Option) (int64, error) {
+	return s.signed(key, 10, 64, "int64", options...)
+}
+
+// IntBase attempts to convert the value for the requested key into an int64, interpreting it in the given base,
+// i.e. "022" as octal with base 8, or "ff" as hexadecimal with base 16. The base must be between 2 and 36, or 0 in
+// which case the base is implied by the value's prefix, as for strconv.ParseInt.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the base is invalid.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) IntBase(key string, base int, options ...Option) (int64, error) {
+	return s.signed(key, base, 64, fmt.Sprintf("int64 (base %d)", base), options...)
+}
+
+// Port attempts to convert the value for the requested key into a port number between 1 and 65535. To accept 0
+// as well, use Uint16 with the option ValidatePort(true).
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value is not a valid port number.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Port(key string, options ...Option) (uint16, error) {
 	val, err := s.String(key, options...)
 	if err != nil || val == "" {
-		return nil, err
+		return 0, err
 	}
-	ss := strings.Split(val, separator)
-	return ss, nil
+	port, err := strconv.ParseUint(val, 10, 16)
+	if err != nil || port == 0 {
+		return 0, ConversionError{key, val, "port"}
+	}
+	return uint16(port), nil
 }
 
-// Int attempts to convert the value for the requested key into an int.
+// Uint8 attempts to convert the value for the requested key into a uint8.
 // A NoKeyError is returned if the key is required but does not exist.
-// A ConversionError is returned if type conversion fails.
+// A ConversionError is returned if type conversion fails, including when the value overflows a uint8.
 // A RegExpValidationError, EnumValidationError or custom error may be returned depending
 // on which validation options were passed.
-func (s Params) Int(key string, options ...Option) (int, error) {
+func (s Params) Uint8(key string, options ...Option) (uint8, error) {
+	u, err := s.unsigned(key, 8, "uint8", options...)
+	return uint8(u), err
+}
+
+// Uint16 attempts to convert the value for the requested key into a uint16.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value overflows a uint16.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Uint16(key string, options ...Option) (uint16, error) {
+	u, err := s.unsigned(key, 16, "uint16", options...)
+	return uint16(u), err
+}
+
+// Uint32 attempts to convert the value for the requested key into a uint32.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value overflows a uint32.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Uint32(key string, options ...Option) (uint32, error) {
+	u, err := s.unsigned(key, 32, "uint32", options...)
+	return uint32(u), err
+}
+
+// Uint64 attempts to convert the value for the requested key into a uint64.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value overflows a uint64.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Uint64(key string, options ...Option) (uint64, error) {
+	return s.unsigned(key, 64, "uint64", options...)
+}
+
+// signed converts the value for the requested key, in the given base, into a signed integer that fits into the
+// given bit size. The datatype is used for reporting conversion errors.
+func (s Params) signed(key string, base, bitSize int, datatype string, options ...Option) (int64, error) {
 	val, err := s.String(key, options...)
 	if err != nil || val == "" {
-		return 0, err
+		return 0, checkNonZero(key, err, options)
 	}
-	i, err := strconv.Atoi(val)
+	i, err := strconv.ParseInt(val, base, bitSize)
 	if err != nil {
-		return 0, ConversionError{key, val, "int"}
+		return 0, ConversionError{key, val, datatype}
+	}
+	if i == 0 {
+		return 0, checkNonZero(key, nil, options)
 	}
 	return i, nil
 }
 
+// unsigned converts the value for the requested key into an unsigned integer that fits into the given bit size.
+// The datatype is used for reporting conversion errors.
+func (s Params) unsigned(key string, bitSize int, datatype string, options ...Option) (uint64, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return 0, checkNonZero(key, err, options)
+	}
+	u, err := strconv.ParseUint(val, 10, bitSize)
+	if err != nil {
+		return 0, ConversionError{key, val, datatype}
+	}
+	if u == 0 {
+		return 0, checkNonZero(key, nil, options)
+	}
+	return u, nil
+}
+
 // Float attempts to convert the value for the requested key into a float64.
 // A NoKeyError is returned if the key is required but does not exist.
 // A ConversionError is returned if type conversion fails.
@@ -274,15 +1848,89 @@ func (s Params) Int(key string, options ...Option) (int, error) {
 func (s Params) Float(key string, options ...Option) (float64, error) {
 	val, err := s.String(key, options...)
 	if err != nil || val == "" {
-		return 0, err
+		return 0, checkNonZero(key, err, options)
 	}
 	f, err := strconv.ParseFloat(val, 64)
 	if err != nil {
 		return 0, ConversionError{key, val, "float64"}
 	}
+	if f == 0 {
+		return 0, checkNonZero(key, nil, options)
+	}
 	return f, nil
 }
 
+// byteUnits contains the factors of the size suffixes accepted by Bytes.
+var byteUnits = map[string]int64{
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"PB":  1000 * 1000 * 1000 * 1000 * 1000,
+	"EB":  1000 * 1000 * 1000 * 1000 * 1000 * 1000,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+	"PiB": 1 << 50,
+	"EiB": 1 << 60,
+}
+
+// Bytes attempts to convert the value for the requested key into a number of bytes, i.e. 10000000 for "10MB" or
+// 524288 for "512KiB". Decimal (KB, MB, GB, TB, PB, EB) and binary (KiB, MiB, GiB, TiB, PiB, EiB) suffixes are
+// accepted, as well as "B" and plain integers, which are taken as bytes. Suffixes are matched case-insensitively,
+// so "10mb" and "10Mb" are the same as "10MB", since sizes in bits aren't supported. Whitespace between the number
+// and the suffix is allowed, and the number may have a fractional part as long as the result is a whole number of
+// bytes, i.e. "1.5KiB".
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails, including when the value is negative or overflows an
+// int64.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Bytes(key string, options ...Option) (int64, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return 0, checkNonZero(key, err, options)
+	}
+
+	var suffix string
+	for unit := range byteUnits {
+		if len(val) >= len(unit) && strings.EqualFold(val[len(val)-len(unit):], unit) && len(unit) > len(suffix) {
+			suffix = unit
+		}
+	}
+	num, factor := strings.TrimSpace(val[:len(val)-len(suffix)]), int64(1)
+	if suffix != "" {
+		factor = byteUnits[suffix]
+	}
+	if strings.HasPrefix(num, "-") {
+		return 0, ConversionError{key, val, "Bytes"}
+	}
+
+	var b int64
+	if i, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if b = i * factor; i != 0 && b/i != factor {
+			return 0, ConversionError{key, val, "Bytes"}
+		}
+	} else {
+		// Exponents, hexadecimal numbers, infinity and NaN are accepted by ParseFloat, but not as sizes.
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil || strings.ContainsAny(num, "eEnNxX") {
+			return 0, ConversionError{key, val, "Bytes"}
+		}
+		f *= float64(factor)
+		if f != math.Trunc(f) || f >= math.MaxInt64 || f < math.MinInt64 {
+			return 0, ConversionError{key, val, "Bytes"}
+		}
+		b = int64(f)
+	}
+	if b == 0 {
+		return 0, checkNonZero(key, nil, options)
+	}
+	return b, nil
+}
+
 // Bool attempts to convert the value for the requested key into a bool.
 // Acceptable values for truth are: t, true, y, yes and 1.
 // Acceptable values for falsehood are: f, false, n, no and 0.
@@ -295,13 +1943,41 @@ func (s Params) Bool(key string, options ...Option) (bool, error) {
 	if err != nil || val == "" {
 		return false, err
 	}
+	b, ok := parseBool(val)
+	if !ok {
+		return false, ConversionError{key, val, "bool"}
+	}
+	return b, nil
+}
+
+// BoolPtr attempts to convert the value for the requested key into a pointer to a bool. Unlike Bool, BoolPtr
+// makes it possible to distinguish between unset keys and keys that are explicitly false: nil is returned for
+// missing/empty keys, and a pointer to the value otherwise. The same values as for Bool are accepted.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) BoolPtr(key string, options ...Option) (*bool, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return nil, err
+	}
+	b, ok := parseBool(val)
+	if !ok {
+		return nil, ConversionError{key, val, "bool"}
+	}
+	return &b, nil
+}
+
+// parseBool converts the given value into a bool. The return value ok is false if the value isn't recognized.
+func parseBool(val string) (b, ok bool) {
 	switch val {
 	case "t", "true", "y", "yes", "on", "1":
-		return true, nil
+		return true, true
 	case "f", "false", "n", "no", "off", "0":
-		return false, nil
+		return false, true
 	}
-	return false, ConversionError{key, val, "bool"}
+	return false, false
 }
 
 // Duration attempts to convert the value for the requested key into a time.Duration.
@@ -312,12 +1988,15 @@ func (s Params) Bool(key string, options ...Option) (bool, error) {
 func (s Params) Duration(key string, options ...Option) (time.Duration, error) {
 	val, err := s.String(key, options...)
 	if err != nil || val == "" {
-		return 0, err
+		return 0, checkNonZero(key, err, options)
 	}
 	d, err := time.ParseDuration(val)
 	if err != nil {
 		return 0, ConversionError{key, val, "Duration"}
 	}
+	if d == 0 {
+		return 0, checkNonZero(key, nil, options)
+	}
 	return d, nil
 }
 
@@ -342,16 +2021,133 @@ func (s Params) Time(key, format string, options ...Option) (time.Time, error) {
 	return t, nil
 }
 
+// TimeMulti attempts to convert the value for the requested key into a time.Time using each of the given time
+// formats in order, returning the first successful result. This is useful when timestamps come in different
+// shapes, i.e. RFC3339 and "2006-01-02". If no formats are given, timestamps are parsed as RFC3339.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if none of the formats match.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) TimeMulti(key string, formats []string, options ...Option) (time.Time, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return time.Time{}, err
+	}
+	if len(formats) == 0 {
+		formats = []string{time.RFC3339}
+	}
+	for _, format := range formats {
+		if t, err := time.Parse(format, val); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ConversionError{key, val, "Time"}
+}
+
+// RelativeTime attempts to convert the value for the requested key into a time.Time relative to the given base.
+// The following forms are accepted:
+// - "now": the base time itself
+// - "+<duration>" or "-<duration>": the base time plus/minus a duration as accepted by time.ParseDuration, i.e. "+24h"
+// - "midnight" or "today": the start of the base time's day
+// - "tomorrow" and "yesterday": the start of the day after/before the base time's day
+// - an RFC3339 timestamp, which is parsed the same way as with Time
+// Anchors are case-insensitive, so "Now" is the same as "now", and are resolved in the base time's location.
+// Duration units are case-sensitive as with time.ParseDuration, so "+1M" is rejected rather than being mistaken
+// for one minute or one month.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the value is not in one of the accepted forms.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) RelativeTime(key string, base time.Time, options ...Option) (time.Time, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return time.Time{}, err
+	}
+
+	midnight := time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, base.Location())
+	switch strings.ToLower(val) {
+	case "now":
+		return base, nil
+	case "midnight", "today":
+		return midnight, nil
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	if strings.HasPrefix(val, "+") || strings.HasPrefix(val, "-") {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return time.Time{}, ConversionError{key, val, "RelativeTime"}
+		}
+		return base.Add(d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return time.Time{}, ConversionError{key, val, "RelativeTime"}
+	}
+	return t, nil
+}
+
+// Deadline attempts to convert the value for the requested key into a time.Duration, as with Duration, and returns
+// the current time plus that duration, i.e. the expiry time for a value such as "30m". A negative duration yields a
+// deadline in the past. Missing and empty keys yield the zero time rather than the current time.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if type conversion fails.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) Deadline(key string, options ...Option) (time.Time, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return time.Time{}, err
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return time.Time{}, ConversionError{key, val, "Duration"}
+	}
+	return time.Now().Add(d), nil
+}
+
+// Scan parses the value for the requested key according to the given format, as with fmt.Sscanf, storing
+// successive space-separated values into the given targets. This is useful for destructuring composite values,
+// i.e. Scan("resolution", "%dx%d", &width, &height) for a value such as "1920x1080".
+// It returns the number of targets successfully parsed. Missing and empty keys are not scanned, so 0 and a nil
+// error is returned. A ConversionError is returned if the value doesn't match the format.
+func (s Params) Scan(key, format string, targets ...interface{}) (int, error) {
+	val, ok := s[key]
+	if !ok || val == "" {
+		return 0, nil
+	}
+	n, err := fmt.Sscanf(val, format, targets...)
+	if err != nil {
+		return n, ConversionError{key, val, fmt.Sprintf("format %q", format)}
+	}
+	return n, nil
+}
+
+// checkNonZero is called by numeric getters when the resulting value is zero. It returns the given error if it's
+// non-nil, a ZeroValueError if the NonZero option is among the given options, and nil otherwise.
+func checkNonZero(key string, err error, options []Option) error {
+	if err != nil {
+		return err
+	}
+	if newOption(options...).nonZero {
+		return ZeroValueError(key)
+	}
+	return nil
+}
+
 // checkApplyOptions unpacks the given options and checks the given key and value against them.
 // checkApplyOptions returns the original value unaltered if validation succeeds, a default value if one was given
 // and the key does not exist (ok == false), or an empty string and an error if the key was required but does not
 // exist or if value validation failed. Finally, an empty string and a nil error is returned for keys that don't exist
-// but are not required and have no default values.
+// but are not required and have no default values. Value conversions, such as ConvertUnit, are applied to the
+// resolved value before it's validated.
+// Validators are applied in the order given, see activeValidators, and the first failure is returned.
 func checkApplyOptions(key, value string, ok bool, options ...Option) (string, error) {
-	var opt option
-	for _, o := range options {
-		o(&opt)
-	}
+	opt := newOption(options...)
 
 	if value == "" {
 		ok = false
@@ -361,18 +2157,38 @@ check:
 	switch {
 	case !ok && opt.require:
 		return "", NoKeyError(key)
+	case !ok && opt.defaults[key] != "":
+		ok, value = true, opt.defaults[key]
+		goto check
 	case !ok && opt.defaultValue != "":
 		ok, value = true, opt.defaultValue
 		goto check
+	case !ok && opt.defaultFunc != nil:
+		fn := opt.defaultFunc
+		opt.defaultFunc = nil // Called at most once.
+		val, err := fn(key)
+		if err != nil {
+			return "", err
+		}
+		ok, value = val != "", val
+		goto check
 	case !ok:
 		return "", nil
-	case opt.validateRegExp != nil:
-		ok := opt.validateRegExp.MatchString(value)
-		if !ok {
-			return "", RegExpValidationError(key)
+	}
+
+	for _, transform := range opt.transforms {
+		var err error
+		if value, err = transform(key, value); err != nil {
+			return "", err
+		}
+	}
+
+	for _, v := range activeValidators(opt.validators) {
+		err := v.fn(key, value)
+		if opt.trace != nil {
+			opt.trace(v.name, err)
 		}
-	case opt.validateFunc != nil:
-		if err := opt.validateFunc(key, value); err != nil {
+		if err != nil {
 			return "", err
 		}
 	}
@@ -468,19 +2284,98 @@ func diff(first, second map[string]map[string]string) map[string]map[string]stri
 	return res
 }
 
-// MustPrettyPrint returns a string representation of the given configuration
+// QuotePolicy determines when MustPrettyPrint wraps values in quotes, identified by the constants below.
+type QuotePolicy uint8
+
+const (
+	// QuoteNever is the default policy, values are always printed verbatim.
+	QuoteNever QuotePolicy = iota
+
+	// QuoteAmbiguous quotes values that can't be told apart from other values when printed verbatim,
+	// i.e. values with leading/trailing whitespace, control characters such as newlines, or a leading quote.
+	QuoteAmbiguous
+
+	// QuoteAlways quotes every value.
+	QuoteAlways
+)
+
+// PrintOption represents options for pretty-printing a configuration pool.
+type PrintOption func(*printOption)
+
+// printOption is the internal representation of the set of options for pretty-printing.
+type printOption struct {
+	quote QuotePolicy
+}
+
+// Quote sets the quoting policy used for values. Quoted values are escaped using Go string literal
+// syntax, so they can be restored with strconv.Unquote.
+var Quote = func(policy QuotePolicy) PrintOption { return func(o *printOption) { o.quote = policy } }
+
+// quoteValue returns the given value quoted and escaped if required by the given policy,
+// and the value verbatim otherwise.
+func quoteValue(value string, policy QuotePolicy) string {
+	switch {
+	case policy == QuoteAlways:
+		return strconv.Quote(value)
+	case policy == QuoteAmbiguous && isAmbiguous(value):
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// isAmbiguous returns true if the given value can't be printed verbatim without losing information.
+func isAmbiguous(value string) bool {
+	if value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) {
+		return true
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrettyPrintMasked returns a string representation of the configuration pool in the same format as MustPrettyPrint,
+// except that the values of keys containing any of the given mask keys, i.e. "password" or "secret", are replaced
+// with "****" so they can be logged safely. Matching is case-insensitive and substring-based, so "password" matches
+// both "db.password" and "DBPassword". Empty mask keys are ignored. Output for other keys is identical to that of
+// MustPrettyPrint.
+func (p *Pool) PrettyPrintMasked(indent string, maskKeys []string, options ...PrintOption) string {
+	params := p.copyParams()
+	for _, keys := range params {
+		for key := range keys {
+			lower := strings.ToLower(key)
+			for _, mask := range maskKeys {
+				if mask != "" && strings.Contains(lower, strings.ToLower(mask)) {
+					keys[key] = "****"
+					break
+				}
+			}
+		}
+	}
+	return MustPrettyPrint(params, indent, options...)
+}
+
+// PrettyPrint returns a string representation of the given configuration
 // pool, with section names nested in brackets, and key/value pairs listed
-// line-by-line using the given indentation. It panics if it couldn't generate
-// a valid string.
+// line-by-line using the given indentation. An error is returned if it
+// couldn't generate a valid string.
 // Params names, as well as key names within each section, are sorted
 // alphabetically in order to create deterministic and more comparable output.
-func MustPrettyPrint(pool map[string]map[string]string, indent string) string {
+// Values are printed verbatim unless a quoting policy is given via the Quote option.
+func PrettyPrint(pool map[string]map[string]string, indent string, options ...PrintOption) (string, error) {
+	var opt printOption
+	for _, o := range options {
+		o(&opt)
+	}
+
 	var out strings.Builder
+	var err error
 
 	writeString := func(s string) {
-		_, err := out.WriteString(s)
-		if err != nil {
-			panic(err)
+		if err == nil {
+			_, err = out.WriteString(s)
 		}
 	}
 
@@ -498,9 +2393,21 @@ func MustPrettyPrint(pool map[string]map[string]string, indent string) string {
 		sort.Strings(params)
 		writeString("\n[" + sec + "]\n")
 		for _, key := range params {
-			writeString(indent + key + ": " + pool[sec][key] + "\n")
+			writeString(indent + key + ": " + quoteValue(pool[sec][key], opt.quote) + "\n")
 		}
 	}
 
-	return strings.Trim(out.String(), "\n")
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(out.String(), "\n"), nil
+}
+
+// MustPrettyPrint works like PrettyPrint, but panics if it couldn't generate a valid string.
+func MustPrettyPrint(pool map[string]map[string]string, indent string, options ...PrintOption) string {
+	s, err := PrettyPrint(pool, indent, options...)
+	if err != nil {
+		panic(err)
+	}
+	return s
 }
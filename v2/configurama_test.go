@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -17,6 +18,10 @@ var (
 	// empty is a convenience map that refers to the empty configuration pool.
 	empty = map[string]map[string]string{}
 
+	// ipv4RegExp and hostnameRegExp are simplified regular expressions for matching IPv4 addresses and hostnames.
+	ipv4RegExp     = regexp.MustCompile(`^[0-9]{1,3}(\.[0-9]{1,3}){3}$`)
+	hostnameRegExp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
 	// notABCError is used by validateABC.
 	notABCError = errors.New("not a,b,c")
 
@@ -27,6 +32,16 @@ var (
 		}
 		return notABCError
 	}
+
+	// errNoDefault is returned by failingDefault.
+	errNoDefault = errors.New("no default")
+
+	// keyDefault and emptyDefault are default functions returning the key itself and an empty string, respectively.
+	keyDefault   = func(key string) (string, error) { return key, nil }
+	emptyDefault = func(key string) (string, error) { return "", nil }
+
+	// failingDefault is a default function that always fails.
+	failingDefault = func(key string) (string, error) { return "", errNoDefault }
 )
 
 func TestRaw(t *testing.T) {
@@ -43,6 +58,11 @@ func TestRaw(t *testing.T) {
 	if !reflect.DeepEqual(params, actual) {
 		t.Error("expected raw pool data to equal given pool data")
 	}
+
+	actual["dev"]["db.host"] = "example.com"
+	delete(actual["dev"], "db.username")
+	actual["prod"] = map[string]string{"db.host": "prod.local"}
+	verifyEqual(t, cnf.Raw(), params)
 }
 
 func TestCheckApplyOptions(t *testing.T) {
@@ -122,6 +142,204 @@ func TestCheckApplyOptions(t *testing.T) {
 		"no value, options: validate empty enum (succeeds), default": {
 			"x", "", false, []Option{ValidateEnum(nil), Default("a")}, nil, "a",
 		},
+		"got value, options: with defaults, default": {
+			"x", "y", true, []Option{WithDefaults(Params{"x": "w"}), Default("z")}, nil, "y",
+		},
+		"no value, options: with defaults, default": {
+			"x", "", false, []Option{WithDefaults(Params{"x": "w"}), Default("z")}, nil, "w",
+		},
+		"no value, options: with defaults (missing key), default": {
+			"x", "", false, []Option{WithDefaults(Params{"y": "w"}), Default("z")}, nil, "z",
+		},
+		"no value, options: with defaults (empty value)": {
+			"x", "", false, []Option{WithDefaults(Params{"x": ""})}, nil, "",
+		},
+		"no value, options: with defaults, required": {
+			"x", "", false, []Option{WithDefaults(Params{"x": "w"}), Require()}, NoKeyError("x"), "",
+		},
+		"no value, options: with defaults, validate (fails)": {
+			"x", "", false, []Option{WithDefaults(Params{"x": "w"}), ValidateEnum([]string{"a"})}, EnumValidationError("x"), "",
+		},
+		"got value, options: default func": {
+			"x", "y", true, []Option{DefaultFunc(failingDefault)}, nil, "y",
+		},
+		"no value, options: default func": {
+			"x", "", false, []Option{DefaultFunc(keyDefault)}, nil, "x",
+		},
+		"no value, options: default func, default": {
+			"x", "", false, []Option{DefaultFunc(failingDefault), Default("z")}, nil, "z",
+		},
+		"no value, options: default, default func": {
+			"x", "", false, []Option{Default("z"), DefaultFunc(keyDefault)}, nil, "z",
+		},
+		"no value, options: default func (empty value)": {
+			"x", "", false, []Option{DefaultFunc(emptyDefault)}, nil, "",
+		},
+		"no value, options: default func (fails)": {
+			"x", "", false, []Option{DefaultFunc(failingDefault)}, errNoDefault, "",
+		},
+		"no value, options: with defaults, default func": {
+			"x", "", false, []Option{WithDefaults(Params{"x": "w"}), DefaultFunc(failingDefault)}, nil, "w",
+		},
+		"no value, options: default func, required": {
+			"x", "", false, []Option{DefaultFunc(keyDefault), Require()}, NoKeyError("x"), "",
+		},
+		"no value, options: default func, validate (fails)": {
+			"x", "", false, []Option{DefaultFunc(keyDefault), ValidateEnum([]string{"a"})}, EnumValidationError("x"), "",
+		},
+		"got value, options: transform": {
+			"x", " Y ", true, []Option{Transform(strings.TrimSpace), Transform(strings.ToLower)}, nil, "y",
+		},
+		"no value, options: transform, default": {
+			"x", "", false, []Option{Default("Z"), Transform(strings.ToLower)}, nil, "z",
+		},
+		"got value, options: transform, validate (succeeds)": {
+			"x", "A", true, []Option{ValidateFunc(validateABC), Transform(strings.ToLower)}, nil, "a",
+		},
+		"got value, options: transform, validate (fails)": {
+			"x", "A", true, []Option{ValidateFunc(validateABC), Transform(strings.TrimSpace)}, notABCError, "",
+		},
+		"got value, options: validate any regexp (succeeds)": {
+			"x", "example.com", true, []Option{ValidateAnyRegExp(ipv4RegExp, hostnameRegExp)}, nil, "example.com",
+		},
+		"got value, options: validate any regexp (fails)": {
+			"x", "-example", true, []Option{ValidateAnyRegExp(ipv4RegExp, hostnameRegExp)}, RegExpValidationError("x"), "",
+		},
+		"got value, options: validate any regexp, no patterns (fails)": {
+			"x", "y", true, []Option{ValidateAnyRegExp()}, RegExpValidationError("x"), "",
+		},
+		"got value, options: validate all regexp (succeeds)": {
+			"x", "abc123", true, []Option{ValidateAllRegExp(regexp.MustCompile(`[a-z]`), regexp.MustCompile(`[0-9]`))}, nil, "abc123",
+		},
+		"got value, options: validate all regexp (fails)": {
+			"x", "abc", true, []Option{ValidateAllRegExp(regexp.MustCompile(`[a-z]`), regexp.MustCompile(`[0-9]`))}, RegExpValidationError("x"), "",
+		},
+		"got value, options: validate within root, relative (succeeds)": {
+			"x", "uploads/../images/a.png", true, []Option{ValidateWithinRoot("/srv/data")}, nil, "uploads/../images/a.png",
+		},
+		"got value, options: validate within root, absolute (succeeds)": {
+			"x", "/srv/data/images", true, []Option{ValidateWithinRoot("/srv/data/")}, nil, "/srv/data/images",
+		},
+		"got value, options: validate within root, traversal (fails)": {
+			"x", "../etc/passwd", true, []Option{ValidateWithinRoot("/srv/data")}, PathValidationError("x"), "",
+		},
+		"got value, options: validate within root, nested traversal (fails)": {
+			"x", "images/../../secret", true, []Option{ValidateWithinRoot("/srv/data")}, PathValidationError("x"), "",
+		},
+		"got value, options: validate within root, absolute (fails)": {
+			"x", "/srv/database", true, []Option{ValidateWithinRoot("/srv/data")}, PathValidationError("x"), "",
+		},
+		"got value, options: validate within root, dotted name (succeeds)": {
+			"x", "..hidden", true, []Option{ValidateWithinRoot("/srv/data")}, nil, "..hidden",
+		},
+		"got value, options: validate UUID (succeeds)": {
+			"x", "123e4567-E89B-12d3-a456-426614174000", true, []Option{ValidateUUID()}, nil, "123e4567-E89B-12d3-a456-426614174000",
+		},
+		"got value, options: validate UUID, braces (succeeds)": {
+			"x", "{123e4567-e89b-12d3-a456-426614174000}", true, []Option{ValidateUUID()}, nil, "{123e4567-e89b-12d3-a456-426614174000}",
+		},
+		"got value, options: validate UUID, missing dashes (fails)": {
+			"x", "123e4567e89b12d3a456426614174000", true, []Option{ValidateUUID()}, UUIDValidationError("x"), "",
+		},
+		"got value, options: validate UUID, non-hex (fails)": {
+			"x", "123e4567-e89b-12d3-a456-42661417400g", true, []Option{ValidateUUID()}, UUIDValidationError("x"), "",
+		},
+		"got value, options: validate UUID, unbalanced braces (fails)": {
+			"x", "{123e4567-e89b-12d3-a456-426614174000", true, []Option{ValidateUUID()}, UUIDValidationError("x"), "",
+		},
+		"got value, options: validate power of two (succeeds)": {
+			"x", "4096", true, []Option{ValidatePowerOfTwo()}, nil, "4096",
+		},
+		"got value, options: validate power of two, one (succeeds)": {
+			"x", "1", true, []Option{ValidatePowerOfTwo()}, nil, "1",
+		},
+		"got value, options: validate power of two (fails)": {
+			"x", "1000", true, []Option{ValidatePowerOfTwo()}, PowerOfTwoValidationError("x"), "",
+		},
+		"got value, options: validate power of two, zero (fails)": {
+			"x", "0", true, []Option{ValidatePowerOfTwo()}, PowerOfTwoValidationError("x"), "",
+		},
+		"got value, options: validate power of two, negative (fails)": {
+			"x", "-8", true, []Option{ValidatePowerOfTwo()}, PowerOfTwoValidationError("x"), "",
+		},
+		"got value, options: validate power of two, non-integer (fails)": {
+			"x", "8.0", true, []Option{ValidatePowerOfTwo()}, ConversionError{"x", "8.0", "int64"}, "",
+		},
+		"got value, options: validate positive (succeeds)": {
+			"x", "0.5", true, []Option{ValidatePositive()}, nil, "0.5",
+		},
+		"got value, options: validate positive, zero (fails)": {
+			"x", "0", true, []Option{ValidatePositive()}, SignValidationError{"x", "0", "positive"}, "",
+		},
+		"got value, options: validate positive, negative (fails)": {
+			"x", "-3", true, []Option{ValidatePositive()}, SignValidationError{"x", "-3", "positive"}, "",
+		},
+		"got value, options: validate positive, NaN (fails)": {
+			"x", "NaN", true, []Option{ValidatePositive()}, SignValidationError{"x", "NaN", "positive"}, "",
+		},
+		"got value, options: validate positive, non-numeric (fails)": {
+			"x", "ten", true, []Option{ValidatePositive()}, ConversionError{"x", "ten", "float64"}, "",
+		},
+		"got value, options: validate non-negative, zero (succeeds)": {
+			"x", "0", true, []Option{ValidateNonNegative()}, nil, "0",
+		},
+		"got value, options: validate non-negative (succeeds)": {
+			"x", "42", true, []Option{ValidateNonNegative()}, nil, "42",
+		},
+		"got value, options: validate non-negative, negative (fails)": {
+			"x", "-0.1", true, []Option{ValidateNonNegative()}, SignValidationError{"x", "-0.1", "non-negative"}, "",
+		},
+		"got value, options: validate non-negative, non-numeric (fails)": {
+			"x", "1e", true, []Option{ValidateNonNegative()}, ConversionError{"x", "1e", "float64"}, "",
+		},
+		"got value, options: validate length (succeeds)": {
+			"x", "héllo", true, []Option{ValidateLength(5, 5)}, nil, "héllo",
+		},
+		"got value, options: validate length, no upper bound (succeeds)": {
+			"x", "a very long token", true, []Option{ValidateLength(3, 0)}, nil, "a very long token",
+		},
+		"got value, options: validate length, too short (fails)": {
+			"x", "ab", true, []Option{ValidateLength(3, 8)}, LengthValidationError{"x", 2, 3, 8}, "",
+		},
+		"got value, options: validate length, too long (fails)": {
+			"x", "abcdefghi", true, []Option{ValidateLength(3, 8)}, LengthValidationError{"x", 9, 3, 8}, "",
+		},
+		"no value, options: default, validate length (succeeds)": {
+			"x", "", false, []Option{Default("abc"), ValidateLength(3, 8)}, nil, "abc",
+		},
+		"no value, options: default, validate length (fails)": {
+			"x", "", false, []Option{Default("ab"), ValidateLength(3, 8)}, LengthValidationError{"x", 2, 3, 8}, "",
+		},
+		"no value, options: require, validate length (fails)": {
+			"x", "", false, []Option{Require(), ValidateLength(3, 8)}, NoKeyError("x"), "",
+		},
+		"got value, options: validate enum fold (succeeds)": {
+			"x", "MYSQL", true, []Option{ValidateEnumFold([]string{"mysql", "postgres"})}, nil, "mysql",
+		},
+		"got value, options: validate enum fold, canonical casing (succeeds)": {
+			"x", "mysql", true, []Option{ValidateEnumFold([]string{"MySQL", "Postgres"})}, nil, "MySQL",
+		},
+		"got value, options: validate enum fold (fails)": {
+			"x", "sqlite", true, []Option{ValidateEnumFold([]string{"mysql", "postgres"})}, EnumValidationError("x"), "",
+		},
+		"no value, options: default, validate enum fold (succeeds)": {
+			"x", "", false, []Option{Default("Postgres"), ValidateEnumFold([]string{"mysql", "postgres"})}, nil, "postgres",
+		},
+		"got value, options: validate regexp (succeeds), validate enum (not applied)": {
+			"x", "d", true, []Option{ValidateRegExp(regexp.MustCompile(`^[a-z]$`)), ValidateEnum([]string{"a", "b", "c"})}, nil, "d",
+		},
+		"got value, options: validate func (not applied), validate enum (succeeds)": {
+			"x", "d", true, []Option{ValidateFunc(validateABC), ValidateEnum([]string{"d"})}, nil, "d",
+		},
+		"got value, options: validate enum (succeeds), validate func (succeeds)": {
+			"x", "a", true, []Option{ValidateEnum([]string{"a", "b", "c"}), ValidateFunc(validateABC)}, nil, "a",
+		},
+		"got value, options: validate length (fails), validate regexp": {
+			"x", "abc", true, []Option{ValidateLength(1, 2), ValidateRegExp(regexp.MustCompile(`^[0-9]+$`))}, LengthValidationError{"x", 3, 1, 2}, "",
+		},
+		"got value, options: validate regexp (succeeds), validate length (fails)": {
+			"x", "abc", true, []Option{ValidateRegExp(regexp.MustCompile(`^[a-z]+$`)), ValidateLength(1, 2)}, LengthValidationError{"x", 3, 1, 2}, "",
+		},
 	}
 
 	var actual string
@@ -139,6 +357,58 @@ func TestCheckApplyOptions(t *testing.T) {
 	}
 }
 
+func TestKeys(t *testing.T) {
+	sec := Params{
+		"flag.search": "true",
+		"flag.beta":   "false",
+		"empty":       "",
+	}
+	expected := []string{"empty", "flag.beta", "flag.search"}
+	if actual := sec.Keys(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected keys %v, got %v", expected, actual)
+	}
+	if actual := Params(nil).Keys(); len(actual) != 0 {
+		t.Errorf("expected no keys, got %v", actual)
+	}
+}
+
+func TestRejectUnknownKeys(t *testing.T) {
+	sec := Params{
+		"host":     "localhost",
+		"port":     "3306",
+		"hots":     "typo",
+		"passwrod": "typo",
+	}
+
+	tt := map[string]struct {
+		allowed  []string
+		expected error
+	}{
+		"all keys allowed": {
+			[]string{"host", "port", "hots", "passwrod", "user"}, nil,
+		},
+		"unknown keys": {
+			[]string{"host", "port", "password"}, MultiError{UnknownKeyError("hots"), UnknownKeyError("passwrod")},
+		},
+		"nothing allowed": {
+			nil, MultiError{UnknownKeyError("host"), UnknownKeyError("hots"), UnknownKeyError("passwrod"), UnknownKeyError("port")},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := sec.RejectUnknownKeys(tc.allowed...)
+			if !reflect.DeepEqual(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	if err := (Params{}).RejectUnknownKeys(); err != nil {
+		t.Errorf("expected no error for empty section, got %v", err)
+	}
+}
+
 func TestString(t *testing.T) {
 	cnf := New(map[string]map[string]string{
 		"dev": {
@@ -195,6 +465,9 @@ func TestStrings(t *testing.T) {
 			"long":    "one,two,three,four,five,six,seven,eight,nine,ten",
 			"complex": "14,hello:56,\"quo,ted\",+,",
 			"empty":   "",
+			"scopes":  "read,write",
+			"padded":  " read , ,write ,",
+			"blank":   " , ",
 		},
 	})
 
@@ -235,6 +508,27 @@ func TestStrings(t *testing.T) {
 		"matching key, complex with validation (failed)": {
 			"dev", "complex", ",", []Option{ValidateRegExp(regexp.MustCompile(`^[a-z]{2}`))}, []string{}, true, RegExpValidationError("complex"),
 		},
+		"matching key with subset validation (success)": {
+			"dev", "scopes", ",", []Option{ValidateSubsetOf([]string{"read", "write", "admin"})}, []string{"read", "write"}, true, nil,
+		},
+		"matching key with subset validation (failed)": {
+			"dev", "scopes", ",", []Option{ValidateSubsetOf([]string{"read"})}, []string{}, true, SubsetValidationError{"scopes", "write"},
+		},
+		"matching key, trim elements": {
+			"dev", "padded", ",", []Option{TrimElements()}, []string{"read", "write"}, true, nil,
+		},
+		"matching key, trim elements, nothing left": {
+			"dev", "blank", ",", []Option{TrimElements()}, []string{}, true, nil,
+		},
+		"matching key, trim elements with subset validation (success)": {
+			"dev", "padded", ",", []Option{TrimElements(), ValidateSubsetOf([]string{"read", "write"})}, []string{"read", "write"}, true, nil,
+		},
+		"matching key, without trim elements, subset validation (failed)": {
+			"dev", "padded", ",", []Option{ValidateSubsetOf([]string{"read", "write"})}, []string{}, true, SubsetValidationError{"padded", " read "},
+		},
+		"matching key with subset validation, whole value": {
+			"dev", "scopes", ",", []Option{ValidateSubsetOf([]string{"read,write"})}, []string{}, true, SubsetValidationError{"scopes", "read"},
+		},
 	}
 
 	var actual []string
@@ -256,6 +550,330 @@ func TestStrings(t *testing.T) {
 	}
 }
 
+func TestRequireIf(t *testing.T) {
+	tt := map[string]struct {
+		params    Params
+		condValue string
+		err       error
+	}{
+		"condition met, key present":         {Params{"tls.enabled": "true", "tls.cert": "cert.pem"}, "true", nil},
+		"condition met, key missing":         {Params{"tls.enabled": "true"}, "true", ConditionalKeyError{"tls.cert", "tls.enabled", "true"}},
+		"condition met, key empty":           {Params{"tls.enabled": "true", "tls.cert": ""}, "true", ConditionalKeyError{"tls.cert", "tls.enabled", "true"}},
+		"condition met via bool token":       {Params{"tls.enabled": "on"}, "true", ConditionalKeyError{"tls.cert", "tls.enabled", "on"}},
+		"condition not met":                  {Params{"tls.enabled": "false"}, "true", nil},
+		"condition not met via bool token":   {Params{"tls.enabled": "no"}, "yes", nil},
+		"condition key missing":              {Params{}, "true", nil},
+		"condition key missing, empty value": {Params{}, "", ConditionalKeyError{"tls.cert", "tls.enabled", ""}},
+		"non-bool value":                     {Params{"tls.enabled": "strict"}, "strict", ConditionalKeyError{"tls.cert", "tls.enabled", "strict"}},
+		"non-bool value, not met":            {Params{"tls.enabled": "strict"}, "true", nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.params.RequireIf("tls.cert", "tls.enabled", tc.condValue); err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	sec := Params{
+		"name":       "My Service (EU)",
+		"clean":      "orders",
+		"separators": "--Orders__2024--",
+		"unicode":    "Café Été",
+		"symbols":    "!!!",
+		"empty":      "",
+	}
+
+	tt := map[string]struct {
+		key, expected string
+		options       []Option
+		err           error
+	}{
+		"free-form":            {"name", "my-service-eu", nil, nil},
+		"already a slug":       {"clean", "orders", nil, nil},
+		"separator runs":       {"separators", "orders-2024", nil, nil},
+		"non-ascii":            {"unicode", "caf-t", nil, nil},
+		"no letters or digits": {"symbols", "", nil, ConversionError{"symbols", "!!!", "slug"}},
+		"empty with default":   {"empty", "default-name", []Option{Default("Default Name")}, nil},
+		"missing":              {"unknown", "", nil, nil},
+		"missing, required":    {"unknown", "", []Option{Require()}, NoKeyError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.Slug(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUUID(t *testing.T) {
+	sec := Params{
+		"upper":   "123E4567-E89B-12D3-A456-426614174000",
+		"braces":  "{123e4567-e89b-12d3-a456-426614174000}",
+		"invalid": "not-a-uuid",
+	}
+
+	tt := map[string]struct {
+		key, expected string
+		options       []Option
+		err           error
+	}{
+		"uppercase":         {"upper", "123e4567-e89b-12d3-a456-426614174000", nil, nil},
+		"braces":            {"braces", "123e4567-e89b-12d3-a456-426614174000", nil, nil},
+		"invalid":           {"invalid", "", nil, ConversionError{"invalid", "not-a-uuid", "UUID"}},
+		"missing":           {"unknown", "", nil, nil},
+		"missing, required": {"unknown", "", []Option{Require()}, NoKeyError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.UUID(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	sec := Params{
+		"endpoint": "https://example.com:8443/api?v=1",
+		"relative": "/api",
+		"invalid":  "not a url",
+	}
+
+	tt := map[string]struct {
+		key, expected string
+		options       []Option
+		err           error
+	}{
+		"absolute URL":            {"endpoint", "https://example.com:8443/api?v=1", nil, nil},
+		"relative URL":            {"relative", "", nil, ConversionError{"relative", "/api", "URL"}},
+		"invalid":                 {"invalid", "", nil, ConversionError{"invalid", "not a url", "URL"}},
+		"missing":                 {"unknown", "", nil, nil},
+		"missing with default":    {"unknown", "http://localhost", []Option{Default("http://localhost"), ValidateURL()}, nil},
+		"missing, required":       {"unknown", "", []Option{Require(), ValidateURL()}, NoKeyError("unknown")},
+		"validate URL (fails)":    {"relative", "", []Option{ValidateURL()}, URLValidationError("relative")},
+		"invalid default (fails)": {"unknown", "", []Option{Default("example.com"), ValidateURL()}, URLValidationError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.URL(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if (actual == nil && tc.expected != "") || (actual != nil && actual.String() != tc.expected) {
+				t.Errorf("expected value %q, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIP(t *testing.T) {
+	sec := Params{
+		"ipv4":    "192.168.0.1",
+		"ipv6":    "::1",
+		"invalid": "256.0.0.1",
+	}
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected net.IP
+		err      error
+	}{
+		"IPv4":                 {"ipv4", nil, net.IPv4(192, 168, 0, 1), nil},
+		"IPv6":                 {"ipv6", nil, net.IPv6loopback, nil},
+		"invalid":              {"invalid", nil, nil, ConversionError{"invalid", "256.0.0.1", "IP"}},
+		"missing":              {"unknown", nil, nil, nil},
+		"missing with default": {"unknown", []Option{Default("0.0.0.0"), ValidateIP()}, net.IPv4zero, nil},
+		"missing, required":    {"unknown", []Option{Require(), ValidateIP()}, nil, NoKeyError("unknown")},
+		"validate IP (fails)":  {"invalid", []Option{ValidateIP()}, nil, IPValidationError("invalid")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.IP(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if !actual.Equal(tc.expected) {
+				t.Errorf("expected value %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHostPorts(t *testing.T) {
+	sec := Params{
+		"brokers":  "a:9092, b:09092 ,[::1]:9092",
+		"single":   "localhost:80",
+		"noPort":   "a:9092,b",
+		"badPort":  "a:http",
+		"outRange": "a:65536",
+		"zeroPort": "a:0",
+		"noHost":   ":9092",
+		"empty":    "",
+	}
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected []string
+		err      error
+	}{
+		"multiple":          {"brokers", nil, []string{"a:9092", "b:9092", "[::1]:9092"}, nil},
+		"single":            {"single", nil, []string{"localhost:80"}, nil},
+		"missing port":      {"noPort", nil, nil, ConversionError{"noPort", "b", "host:port"}},
+		"non-numeric port":  {"badPort", nil, nil, ConversionError{"badPort", "a:http", "host:port"}},
+		"port out of range": {"outRange", nil, nil, ConversionError{"outRange", "a:65536", "host:port"}},
+		"zero port":         {"zeroPort", nil, nil, ConversionError{"zeroPort", "a:0", "host:port"}},
+		"missing host":      {"noHost", nil, nil, ConversionError{"noHost", ":9092", "host:port"}},
+		"empty":             {"empty", nil, nil, nil},
+		"missing, required": {"unknown", []Option{Require()}, nil, NoKeyError("unknown")},
+		"subset (success)":  {"single", []Option{ValidateSubsetOf([]string{"localhost:80"})}, []string{"localhost:80"}, nil},
+		"subset (failed)":   {"brokers", []Option{ValidateSubsetOf([]string{"a:9092"})}, nil, SubsetValidationError{"brokers", "b:9092"}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.HostPorts(tc.key, ",", tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected value %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStringMap(t *testing.T) {
+	sec := Params{
+		"limits":   "a=1; b = 2 ;c=3",
+		"url":      "redirect=https://example.com/?x=1",
+		"dup":      "a=1;a=2",
+		"noSep":    "a=1;b",
+		"noKey":    "a=1; =2",
+		"trailing": "a=1;",
+		"gaps":     "a=1;; ;b=2",
+		"sepOnly":  ";",
+		"empty":    "",
+	}
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected map[string]string
+		err      error
+	}{
+		"multiple":             {"limits", nil, map[string]string{"a": "1", "b": "2", "c": "3"}, nil},
+		"separator in value":   {"url", nil, map[string]string{"redirect": "https://example.com/?x=1"}, nil},
+		"duplicate key":        {"dup", nil, map[string]string{"a": "2"}, nil},
+		"missing separator":    {"noSep", nil, nil, ConversionError{"noSep", "b", "key/value pair"}},
+		"missing key":          {"noKey", nil, nil, ConversionError{"noKey", " =2", "key/value pair"}},
+		"trailing separator":   {"trailing", nil, map[string]string{"a": "1"}, nil},
+		"empty pairs":          {"gaps", nil, map[string]string{"a": "1", "b": "2"}, nil},
+		"separator only":       {"sepOnly", nil, map[string]string{}, nil},
+		"empty":                {"empty", nil, map[string]string{}, nil},
+		"missing":              {"unknown", nil, map[string]string{}, nil},
+		"missing, required":    {"unknown", []Option{Require()}, nil, NoKeyError("unknown")},
+		"missing, default":     {"unknown", []Option{Default("x=y")}, map[string]string{"x": "y"}, nil},
+		"validation (success)": {"dup", []Option{ValidateRegExp(regexp.MustCompile(`^a=`))}, map[string]string{"a": "2"}, nil},
+		"validation (failed)":  {"limits", []Option{ValidateRegExp(regexp.MustCompile(`^b=`))}, nil, RegExpValidationError("limits")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.StringMap(tc.key, ";", "=", tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected value %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPairTriple(t *testing.T) {
+	sec := Params{
+		"location": "55.67,12.56",
+		"color":    "255,128,0",
+		"single":   "55.67",
+		"empty":    "",
+	}
+
+	first, second, err := sec.Pair("location", ",")
+	verifyNil(t, err)
+	if first != "55.67" || second != "12.56" {
+		t.Errorf("expected pair %q and %q, got %q and %q", "55.67", "12.56", first, second)
+	}
+
+	r, g, b, err := sec.Triple("color", ",")
+	verifyNil(t, err)
+	if r != "255" || g != "128" || b != "0" {
+		t.Errorf("expected triple %q, %q and %q, got %q, %q and %q", "255", "128", "0", r, g, b)
+	}
+
+	tt := map[string]struct {
+		key      string
+		triple   bool
+		options  []Option
+		expected error
+	}{
+		"pair, too few parts":       {"single", false, nil, ConversionError{"single", "55.67", "Pair"}},
+		"pair, too many parts":      {"color", false, nil, ConversionError{"color", "255,128,0", "Pair"}},
+		"pair, empty":               {"empty", false, nil, nil},
+		"pair, missing, required":   {"unknown", false, []Option{Require()}, NoKeyError("unknown")},
+		"triple, too few parts":     {"location", true, nil, ConversionError{"location", "55.67,12.56", "Triple"}},
+		"triple, missing":           {"unknown", true, nil, nil},
+		"triple, missing, required": {"unknown", true, []Option{Require()}, NoKeyError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var parts []string
+			var err error
+			if tc.triple {
+				var third string
+				first, second, third, err = sec.Triple(tc.key, ",", tc.options...)
+				parts = []string{first, second, third}
+			} else {
+				first, second, err = sec.Pair(tc.key, ",", tc.options...)
+				parts = []string{first, second}
+			}
+			if err != tc.expected {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+			for _, part := range parts {
+				if part != "" {
+					t.Errorf("expected empty parts, got %q", parts)
+				}
+			}
+		})
+	}
+
+	first, second, err = sec.Pair("unknown", ",", Default("1,2"))
+	verifyNil(t, err)
+	if first != "1" || second != "2" {
+		t.Errorf("expected default pair %q and %q, got %q and %q", "1", "2", first, second)
+	}
+}
+
 func TestInt(t *testing.T) {
 	cnf := New(map[string]map[string]string{
 		"dev": {
@@ -319,6 +937,418 @@ func TestInt(t *testing.T) {
 	}
 }
 
+func TestTransform(t *testing.T) {
+	sec := Params{"port": " 3306 "}
+
+	if _, err := sec.Int("port"); err == nil {
+		t.Error("expected conversion error without transform")
+	}
+	port, err := sec.Int("port", Transform(strings.TrimSpace))
+	verifyNil(t, err)
+	if port != 3306 {
+		t.Errorf("expected value %d, got %d", 3306, port)
+	}
+}
+
+func TestSizedInts(t *testing.T) {
+	sec := Params{
+		"small":    "100",
+		"negative": "-100",
+		"medium":   "300",
+		"large":    "70000",
+		"huge":     "5000000000",
+		"enormous": "9223372036854775808",
+		"invalid":  "invalid",
+	}
+
+	int8Getter := func(key string, options ...Option) (int64, error) {
+		i, err := sec.Int8(key, options...)
+		return int64(i), err
+	}
+	int16Getter := func(key string, options ...Option) (int64, error) {
+		i, err := sec.Int16(key, options...)
+		return int64(i), err
+	}
+	int32Getter := func(key string, options ...Option) (int64, error) {
+		i, err := sec.Int32(key, options...)
+		return int64(i), err
+	}
+	int64Getter := func(key string, options ...Option) (int64, error) {
+		return sec.Int64(key, options...)
+	}
+	uint8Getter := func(key string, options ...Option) (int64, error) {
+		u, err := sec.Uint8(key, options...)
+		return int64(u), err
+	}
+	uint16Getter := func(key string, options ...Option) (int64, error) {
+		u, err := sec.Uint16(key, options...)
+		return int64(u), err
+	}
+	uint32Getter := func(key string, options ...Option) (int64, error) {
+		u, err := sec.Uint32(key, options...)
+		return int64(u), err
+	}
+	uint64Getter := func(key string, options ...Option) (int64, error) {
+		u, err := sec.Uint64(key, options...)
+		return int64(u), err
+	}
+
+	tt := map[string]struct {
+		getter   func(key string, options ...Option) (int64, error)
+		key      string
+		options  []Option
+		expected int64
+		err      error
+	}{
+		"int8, in range":               {int8Getter, "small", nil, 100, nil},
+		"int8, negative":               {int8Getter, "negative", nil, -100, nil},
+		"int8, overflow":               {int8Getter, "medium", nil, 0, ConversionError{"medium", "300", "int8"}},
+		"int8, missing with default":   {int8Getter, "unknown", []Option{Default("12")}, 12, nil},
+		"int8, missing, required":      {int8Getter, "unknown", []Option{Require()}, 0, NoKeyError("unknown")},
+		"int16, in range":              {int16Getter, "medium", nil, 300, nil},
+		"int16, overflow":              {int16Getter, "large", nil, 0, ConversionError{"large", "70000", "int16"}},
+		"int32, in range":              {int32Getter, "large", nil, 70000, nil},
+		"int32, overflow":              {int32Getter, "huge", nil, 0, ConversionError{"huge", "5000000000", "int32"}},
+		"int32, invalid":               {int32Getter, "invalid", nil, 0, ConversionError{"invalid", "invalid", "int32"}},
+		"int64, in range":              {int64Getter, "huge", nil, 5000000000, nil},
+		"int64, overflow":              {int64Getter, "enormous", nil, 0, ConversionError{"enormous", "9223372036854775808", "int64"}},
+		"int64, invalid":               {int64Getter, "invalid", nil, 0, ConversionError{"invalid", "invalid", "int64"}},
+		"int64, missing with default":  {int64Getter, "unknown", []Option{Default("-7")}, -7, nil},
+		"int64, missing, required":     {int64Getter, "unknown", []Option{Require()}, 0, NoKeyError("unknown")},
+		"int64, validate enum":         {int64Getter, "small", []Option{ValidateEnum([]string{"1"})}, 0, EnumValidationError("small")},
+		"uint8, in range":              {uint8Getter, "small", nil, 100, nil},
+		"uint8, overflow":              {uint8Getter, "medium", nil, 0, ConversionError{"medium", "300", "uint8"}},
+		"uint8, negative":              {uint8Getter, "negative", nil, 0, ConversionError{"negative", "-100", "uint8"}},
+		"uint16, overflow":             {uint16Getter, "large", nil, 0, ConversionError{"large", "70000", "uint16"}},
+		"uint16, missing with default": {uint16Getter, "unknown", []Option{Default("65535")}, 65535, nil},
+		"uint32, in range":             {uint32Getter, "large", nil, 70000, nil},
+		"uint32, overflow":             {uint32Getter, "huge", nil, 0, ConversionError{"huge", "5000000000", "uint32"}},
+		"uint64, in range":             {uint64Getter, "huge", nil, 5000000000, nil},
+		"uint64, negative":             {uint64Getter, "negative", nil, 0, ConversionError{"negative", "-100", "uint64"}},
+		"uint64, missing with default": {uint64Getter, "unknown", []Option{Default("42")}, 42, nil},
+		"uint64, missing, required":    {uint64Getter, "unknown", []Option{Require()}, 0, NoKeyError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := tc.getter(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBytes(t *testing.T) {
+	sec := Params{
+		"plain":      "1024",
+		"bytes":      "12B",
+		"decimal":    "10MB",
+		"binary":     "512KiB",
+		"spaced":     "2 GiB",
+		"fraction":   "1.5KiB",
+		"partial":    "1.5B",
+		"unknown":    "10XB",
+		"lowercase":  "10mb",
+		"mixedCase":  "10Mb",
+		"lowerBin":   "2kib",
+		"exa":        "2EB",
+		"exbi":       "1EiB",
+		"exaOver":    "8EiB",
+		"exponent":   "1e3KB",
+		"overflow":   "9000000TiB",
+		"zero":       "0MB",
+		"invalid":    "invalid",
+		"suffixOnly": "MB",
+		"negative":   "-5MB",
+		"negFloat":   "-1.5KiB",
+	}
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected int64
+		err      error
+	}{
+		"plain integer":        {"plain", nil, 1024, nil},
+		"bytes":                {"bytes", nil, 12, nil},
+		"decimal suffix":       {"decimal", nil, 10000000, nil},
+		"binary suffix":        {"binary", nil, 524288, nil},
+		"whitespace":           {"spaced", nil, 2 << 30, nil},
+		"fraction":             {"fraction", nil, 1536, nil},
+		"partial byte":         {"partial", nil, 0, ConversionError{"partial", "1.5B", "Bytes"}},
+		"unknown suffix":       {"unknown", nil, 0, ConversionError{"unknown", "10XB", "Bytes"}},
+		"lowercase suffix":     {"lowercase", nil, 10000000, nil},
+		"mixed case suffix":    {"mixedCase", nil, 10000000, nil},
+		"lowercase binary":     {"lowerBin", nil, 2048, nil},
+		"exabytes":             {"exa", nil, 2000000000000000000, nil},
+		"exbibytes":            {"exbi", nil, 1 << 60, nil},
+		"exbibytes overflow":   {"exaOver", nil, 0, ConversionError{"exaOver", "8EiB", "Bytes"}},
+		"exponent":             {"exponent", nil, 0, ConversionError{"exponent", "1e3KB", "Bytes"}},
+		"overflow":             {"overflow", nil, 0, ConversionError{"overflow", "9000000TiB", "Bytes"}},
+		"suffix only":          {"suffixOnly", nil, 0, ConversionError{"suffixOnly", "MB", "Bytes"}},
+		"invalid":              {"invalid", nil, 0, ConversionError{"invalid", "invalid", "Bytes"}},
+		"negative":             {"negative", nil, 0, ConversionError{"negative", "-5MB", "Bytes"}},
+		"negative fraction":    {"negFloat", nil, 0, ConversionError{"negFloat", "-1.5KiB", "Bytes"}},
+		"zero, non-zero":       {"zero", []Option{NonZero()}, 0, ZeroValueError("zero")},
+		"missing with default": {"missing", []Option{Default("1KB")}, 1000, nil},
+		"missing, required":    {"missing", []Option{Require()}, 0, NoKeyError("missing")},
+		"validate (fails)":     {"decimal", []Option{ValidateEnum([]string{"1MB"})}, 0, EnumValidationError("decimal")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.Bytes(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIntBase(t *testing.T) {
+	sec := Params{
+		"umask":   "022",
+		"color":   "ff",
+		"prefix":  "0x1f",
+		"zero":    "000",
+		"invalid": "8",
+	}
+
+	tt := map[string]struct {
+		key      string
+		base     int
+		options  []Option
+		expected int64
+		err      error
+	}{
+		"octal":                {"umask", 8, nil, 18, nil},
+		"hexadecimal":          {"color", 16, nil, 255, nil},
+		"implied base":         {"prefix", 0, nil, 31, nil},
+		"decimal":              {"umask", 10, nil, 22, nil},
+		"invalid digit":        {"invalid", 8, nil, 0, ConversionError{"invalid", "8", "int64 (base 8)"}},
+		"invalid base":         {"umask", 1, nil, 0, ConversionError{"umask", "022", "int64 (base 1)"}},
+		"zero, non-zero":       {"zero", 8, []Option{NonZero()}, 0, ZeroValueError("zero")},
+		"missing with default": {"unknown", 16, []Option{Default("1f")}, 31, nil},
+		"missing, required":    {"unknown", 8, []Option{Require()}, 0, NoKeyError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.IntBase(tc.key, tc.base, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPort(t *testing.T) {
+	sec := Params{
+		"http":     "80",
+		"max":      "65535",
+		"zero":     "0",
+		"overflow": "65536",
+		"negative": "-1",
+		"invalid":  "http",
+	}
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected uint16
+		err      error
+	}{
+		"valid":                {"http", nil, 80, nil},
+		"upper bound":          {"max", nil, 65535, nil},
+		"zero":                 {"zero", nil, 0, ConversionError{"zero", "0", "port"}},
+		"overflow":             {"overflow", nil, 0, ConversionError{"overflow", "65536", "port"}},
+		"negative":             {"negative", nil, 0, ConversionError{"negative", "-1", "port"}},
+		"invalid":              {"invalid", nil, 0, ConversionError{"invalid", "http", "port"}},
+		"missing with default": {"unknown", []Option{Default("8080")}, 8080, nil},
+		"missing, required":    {"unknown", []Option{Require()}, 0, NoKeyError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.Port(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidateNotIn(t *testing.T) {
+	forbidden := []string{"changeme", "password"}
+
+	tt := map[string]struct {
+		value      string
+		ignoreCase bool
+		err        error
+	}{
+		"allowed":                 {"s3cr3t!", false, nil},
+		"forbidden":               {"changeme", false, ForbiddenValueError("x")},
+		"different case":          {"ChangeMe", false, nil},
+		"different case, ignored": {"ChangeMe", true, ForbiddenValueError("x")},
+		"substring":               {"password1", true, nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := checkApplyOptions("x", tc.value, true, ValidateNotIn(forbidden, tc.ignoreCase))
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if err != nil && strings.Contains(err.Error(), tc.value) {
+				t.Errorf("expected error not to contain the value, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatePort(t *testing.T) {
+	tt := map[string]struct {
+		value     string
+		allowZero bool
+		err       error
+	}{
+		"lower bound":   {"1", false, nil},
+		"upper bound":   {"65535", false, nil},
+		"zero":          {"0", false, PortValidationError("x")},
+		"zero, allowed": {"0", true, nil},
+		"overflow":      {"65536", true, PortValidationError("x")},
+		"negative":      {"-1", true, PortValidationError("x")},
+		"non-numeric":   {"http", false, PortValidationError("x")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if _, err := checkApplyOptions("x", tc.value, true, ValidatePort(tc.allowZero)); err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestNonZero(t *testing.T) {
+	sec := Params{
+		"zero":     "0",
+		"port":     "3306",
+		"ratio":    "0.0",
+		"interval": "0s",
+		"empty":    "",
+	}
+
+	intGetter := func(key string, options ...Option) (float64, error) {
+		i, err := sec.Int(key, options...)
+		return float64(i), err
+	}
+	uint16Getter := func(key string, options ...Option) (float64, error) {
+		u, err := sec.Uint16(key, options...)
+		return float64(u), err
+	}
+	floatGetter := func(key string, options ...Option) (float64, error) { return sec.Float(key, options...) }
+	durationGetter := func(key string, options ...Option) (float64, error) {
+		d, err := sec.Duration(key, options...)
+		return float64(d), err
+	}
+
+	tt := map[string]struct {
+		getter   func(key string, options ...Option) (float64, error)
+		key      string
+		options  []Option
+		expected float64
+		err      error
+	}{
+		"int, zero without option":       {intGetter, "zero", nil, 0, nil},
+		"int, zero":                      {intGetter, "zero", []Option{NonZero()}, 0, ZeroValueError("zero")},
+		"int, non-zero":                  {intGetter, "port", []Option{NonZero()}, 3306, nil},
+		"int, empty":                     {intGetter, "empty", []Option{NonZero()}, 0, ZeroValueError("empty")},
+		"int, missing":                   {intGetter, "unknown", []Option{NonZero()}, 0, ZeroValueError("unknown")},
+		"int, missing with default":      {intGetter, "unknown", []Option{NonZero(), Default("80")}, 80, nil},
+		"int, missing with zero default": {intGetter, "unknown", []Option{NonZero(), Default("0")}, 0, ZeroValueError("unknown")},
+		"int, missing, required":         {intGetter, "unknown", []Option{NonZero(), Require()}, 0, NoKeyError("unknown")},
+		"uint16, zero":                   {uint16Getter, "zero", []Option{NonZero()}, 0, ZeroValueError("zero")},
+		"float, zero":                    {floatGetter, "ratio", []Option{NonZero()}, 0, ZeroValueError("ratio")},
+		"duration, zero":                 {durationGetter, "interval", []Option{NonZero()}, 0, ZeroValueError("interval")},
+		"duration, empty with default":   {durationGetter, "empty", []Option{NonZero(), Default("1ns")}, 1, nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := tc.getter(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %f, got %f", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConvertUnit(t *testing.T) {
+	sec := Params{
+		"distance": "5km",
+		"spaced":   "2.5 km",
+		"short":    "300m",
+		"fraction": "1.5mm",
+		"plain":    "42",
+		"unknown":  "3mi",
+		"invalid":  "km",
+	}
+	distance := ConvertUnit(map[string]float64{"km": 1000, "m": 1, "mm": 0.001}, "m")
+
+	tt := map[string]struct {
+		key      string
+		expected float64
+		err      error
+	}{
+		"known suffix":     {"distance", 5000, nil},
+		"spaced suffix":    {"spaced", 2500, nil},
+		"canonical suffix": {"short", 300, nil},
+		"longest suffix":   {"fraction", 0.0015, nil},
+		"no suffix":        {"plain", 42, nil},
+		"unknown suffix":   {"unknown", 0, ConversionError{"unknown", "3mi", `unit "m"`}},
+		"missing number":   {"invalid", 0, ConversionError{"invalid", "km", `unit "m"`}},
+		"missing key":      {"missing", 0, nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.Float(tc.key, distance)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %f, got %f", tc.expected, actual)
+			}
+		})
+	}
+
+	i, err := sec.Int("distance", distance, ValidateIntegral())
+	verifyNil(t, err)
+	if i != 5000 {
+		t.Errorf("expected value %d, got %d", 5000, i)
+	}
+	if i, err = sec.Int("missing", distance, Default("2km")); err != nil || i != 2000 {
+		t.Errorf("expected default value %d, got %d, %v", 2000, i, err)
+	}
+}
+
 func TestFloat(t *testing.T) {
 	cnf := New(map[string]map[string]string{
 		"dev": {
@@ -447,6 +1477,47 @@ func TestBool(t *testing.T) {
 	}
 }
 
+func TestBoolPtr(t *testing.T) {
+	sec := Params{
+		"enabled":  "yes",
+		"disabled": "false",
+		"empty":    "",
+		"invalid":  "maybe",
+	}
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected *bool
+		err      error
+	}{
+		"true":                 {"enabled", nil, boolPtr(true), nil},
+		"explicit false":       {"disabled", nil, boolPtr(false), nil},
+		"empty":                {"empty", nil, nil, nil},
+		"missing":              {"unknown", nil, nil, nil},
+		"missing with default": {"unknown", []Option{Default("off")}, boolPtr(false), nil},
+		"missing, required":    {"unknown", []Option{Require()}, nil, NoKeyError("unknown")},
+		"invalid":              {"invalid", nil, nil, ConversionError{"invalid", "maybe", "bool"}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.BoolPtr(tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected value %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// boolPtr returns a pointer to the given bool.
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 func TestDuration(t *testing.T) {
 	cnf := New(map[string]map[string]string{
 		"dev": {
@@ -572,6 +1643,182 @@ func TestTime(t *testing.T) {
 	}
 }
 
+func TestTimeMulti(t *testing.T) {
+	sec := Params{
+		"rfc3339":  "2021-11-06T22:30:00Z",
+		"date":     "2021-11-06",
+		"datetime": "2021-11-06 22:30:00",
+		"invalid":  "06/11/2021",
+	}
+	formats := []string{time.RFC3339, "2006-01-02", "2006-01-02 15:04:05"}
+
+	tt := map[string]struct {
+		key      string
+		formats  []string
+		options  []Option
+		expected time.Time
+		err      error
+	}{
+		"first format":             {"rfc3339", formats, nil, time.Date(2021, 11, 6, 22, 30, 0, 0, time.UTC), nil},
+		"second format":            {"date", formats, nil, time.Date(2021, 11, 6, 0, 0, 0, 0, time.UTC), nil},
+		"third format":             {"datetime", formats, nil, time.Date(2021, 11, 6, 22, 30, 0, 0, time.UTC), nil},
+		"no matching format":       {"invalid", formats, nil, time.Time{}, ConversionError{"invalid", "06/11/2021", "Time"}},
+		"no formats":               {"rfc3339", nil, nil, time.Date(2021, 11, 6, 22, 30, 0, 0, time.UTC), nil},
+		"no formats, not RFC3339":  {"date", nil, nil, time.Time{}, ConversionError{"date", "2021-11-06", "Time"}},
+		"missing key with default": {"unknown", formats, []Option{Default("2020-01-01")}, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), nil},
+		"missing key, required":    {"unknown", formats, []Option{Require()}, time.Time{}, NoKeyError("unknown")},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.TimeMulti(tc.key, tc.formats, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if !actual.Equal(tc.expected) {
+				t.Errorf("expected value %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	sec := Params{
+		"now":       "now",
+		"later":     "+24h",
+		"earlier":   "-90m",
+		"midnight":  "Midnight",
+		"tomorrow":  "tomorrow",
+		"yesterday": "yesterday",
+		"explicit":  "2020-01-02T03:04:05Z",
+		"invalid":   "someday",
+		"badOffset": "+1 day",
+		"upperUnit": "+24H",
+		"mixedUnit": "-1h30M",
+		"month":     "+1M",
+		"upperNow":  "NOW",
+	}
+	base := time.Date(2021, 6, 15, 13, 30, 0, 0, time.UTC)
+	midnight := time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected time.Time
+		err      error
+	}{
+		"now":                      {"now", nil, base, nil},
+		"positive offset":          {"later", nil, base.Add(24 * time.Hour), nil},
+		"negative offset":          {"earlier", nil, base.Add(-90 * time.Minute), nil},
+		"uppercase anchor":         {"upperNow", nil, base, nil},
+		"uppercase unit":           {"upperUnit", nil, time.Time{}, ConversionError{"upperUnit", "+24H", "RelativeTime"}},
+		"mixed case units":         {"mixedUnit", nil, time.Time{}, ConversionError{"mixedUnit", "-1h30M", "RelativeTime"}},
+		"ambiguous unit":           {"month", nil, time.Time{}, ConversionError{"month", "+1M", "RelativeTime"}},
+		"midnight":                 {"midnight", nil, midnight, nil},
+		"tomorrow":                 {"tomorrow", nil, midnight.AddDate(0, 0, 1), nil},
+		"yesterday":                {"yesterday", nil, midnight.AddDate(0, 0, -1), nil},
+		"explicit timestamp":       {"explicit", nil, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), nil},
+		"unrecognized":             {"invalid", nil, time.Time{}, ConversionError{"invalid", "someday", "RelativeTime"}},
+		"invalid offset":           {"badOffset", nil, time.Time{}, ConversionError{"badOffset", "+1 day", "RelativeTime"}},
+		"missing key with default": {"unknown", []Option{Default("+1h")}, base.Add(time.Hour), nil},
+		"missing key, required":    {"unknown", []Option{Require()}, time.Time{}, NoKeyError("unknown")},
+		"missing key":              {"unknown", nil, time.Time{}, nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := sec.RelativeTime(tc.key, base, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if !actual.Equal(tc.expected) {
+				t.Errorf("expected value %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	sec := Params{
+		"lease":   "30m",
+		"expired": "-1h",
+		"invalid": "soon",
+	}
+
+	tt := map[string]struct {
+		key     string
+		options []Option
+		offset  time.Duration
+		err     error
+	}{
+		"positive duration":        {"lease", nil, 30 * time.Minute, nil},
+		"negative duration":        {"expired", nil, -time.Hour, nil},
+		"missing key with default": {"unknown", []Option{Default("5s")}, 5 * time.Second, nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			before := time.Now()
+			actual, err := sec.Deadline(tc.key, tc.options...)
+			after := time.Now()
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual.Before(before.Add(tc.offset)) || actual.After(after.Add(tc.offset)) {
+				t.Errorf("expected value between %s and %s, got %s", before.Add(tc.offset), after.Add(tc.offset), actual)
+			}
+		})
+	}
+
+	if _, err := sec.Deadline("invalid"); err != (ConversionError{"invalid", "soon", "Duration"}) {
+		t.Errorf("expected conversion error, got %v", err)
+	}
+	if _, err := sec.Deadline("unknown", Require()); err != NoKeyError("unknown") {
+		t.Errorf("expected error %v, got %v", NoKeyError("unknown"), err)
+	}
+	if actual, err := sec.Deadline("unknown"); err != nil || !actual.IsZero() {
+		t.Errorf("expected zero time for missing key, got %s, %v", actual, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	sec := Params{
+		"resolution": "1920x1080",
+		"version":    "v1.2.3-build45",
+		"invalid":    "widex1080",
+		"empty":      "",
+	}
+
+	var width, height int
+	n, err := sec.Scan("resolution", "%dx%d", &width, &height)
+	verifyNil(t, err)
+	if n != 2 || width != 1920 || height != 1080 {
+		t.Errorf("expected 2 values 1920 and 1080, got %d values %d and %d", n, width, height)
+	}
+
+	var major, minor, patch, build int
+	n, err = sec.Scan("version", "v%d.%d.%d-build%d", &major, &minor, &patch, &build)
+	verifyNil(t, err)
+	if n != 4 || major != 1 || minor != 2 || patch != 3 || build != 45 {
+		t.Errorf("expected 4 values 1, 2, 3 and 45, got %d values %d, %d, %d and %d", n, major, minor, patch, build)
+	}
+
+	n, err = sec.Scan("invalid", "%dx%d", &width, &height)
+	expected := ConversionError{"invalid", "widex1080", `format "%dx%d"`}
+	if err != expected {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+	if n != 0 {
+		t.Errorf("expected 0 values, got %d", n)
+	}
+
+	for _, key := range []string{"empty", "unknown"} {
+		if n, err = sec.Scan(key, "%d", &width); n != 0 || err != nil {
+			t.Errorf("expected 0 values and no error for key %q, got %d and %v", key, n, err)
+		}
+	}
+}
+
 func TestGet(t *testing.T) {
 	c := New(map[string]map[string]string{"Hero": {
 		"name":           "Peter Parker",
@@ -608,6 +1855,30 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetOr(t *testing.T) {
+	c := New(map[string]map[string]string{"Hero": {
+		"name":  "Peter Parker",
+		"alias": "",
+	}})
+
+	tt := map[string]struct {
+		section, key, expected string
+	}{
+		"existing key":    {"Hero", "name", "Peter Parker"},
+		"empty value":     {"Hero", "alias", "fallback"},
+		"missing key":     {"Hero", "score", "fallback"},
+		"missing section": {"Enemy", "name", "fallback"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if actual := c.GetOr(tc.section, tc.key, "fallback"); actual != tc.expected {
+				t.Errorf("expected value %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func TestSet(t *testing.T) {
 	c := New(map[string]map[string]string{"Hero": {
 		"name":           "Peter Parker",
@@ -647,6 +1918,58 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestCompareAndSwap(t *testing.T) {
+	tt := map[string]struct {
+		section, key, old, new string
+		swapped                bool
+		expected               string
+	}{
+		"matching value":         {"Hero", "name", "Peter Parker", "Miles Morales", true, "Miles Morales"},
+		"mismatching value":      {"Hero", "name", "Bruce Wayne", "Miles Morales", false, "Peter Parker"},
+		"missing key":            {"Hero", "city", "New York", "Queens", false, ""},
+		"missing key, empty":     {"Hero", "city", "", "Queens", true, "Queens"},
+		"missing section":        {"Enemy", "name", "Peter Parker", "Miles Morales", false, ""},
+		"missing section, empty": {"Enemy", "name", "", "Harry Osborne", false, ""},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			c := New(map[string]map[string]string{"Hero": {"name": "Peter Parker"}})
+			if swapped := c.CompareAndSwap(tc.section, tc.key, tc.old, tc.new); swapped != tc.swapped {
+				t.Errorf("expected swapped to be %t, got %t", tc.swapped, swapped)
+			}
+			if value, _ := c.Get(tc.section, tc.key); value != tc.expected {
+				t.Errorf("expected value to equal %q, got %q", tc.expected, value)
+			}
+		})
+	}
+
+	// Only one of several concurrent swaps from the same value succeeds.
+	c := New(map[string]map[string]string{"Election": {"leader": ""}})
+	concurrency := 10
+	wins := make(chan bool, concurrency)
+	wg := sync.WaitGroup{}
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func(i int) {
+			defer wg.Done()
+			wins <- c.CompareAndSwap("Election", "leader", "", strconv.Itoa(i))
+		}(i)
+	}
+	wg.Wait()
+	close(wins)
+
+	count := 0
+	for won := range wins {
+		if won {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected exactly one swap to succeed, got %d", count)
+	}
+}
+
 func TestUnset(t *testing.T) {
 	c := New(map[string]map[string]string{"Hero": {
 		"name":           "Peter Parker",
@@ -687,6 +2010,87 @@ func TestUnset(t *testing.T) {
 	}
 }
 
+func TestRenameSection(t *testing.T) {
+	c := New(map[string]map[string]string{"source": {"path": "/in"}})
+	verifyNil(t, c.Merge(map[string]map[string]string{"DB": {"host": "localhost"}}, Report))
+	verifyNil(t, c.Merge(map[string]map[string]string{"sink": {"path": "/out"}}, Report))
+
+	verifyNil(t, c.RenameSection("DB", "database"))
+	verifyEqual(t, c.Raw(), map[string]map[string]string{
+		"source":   {"path": "/in"},
+		"database": {"host": "localhost"},
+		"sink":     {"path": "/out"},
+	})
+	if sections := c.OrderedSections(); !reflect.DeepEqual(sections, []string{"source", "database", "sink"}) {
+		t.Errorf("expected ordered sections %v, got %v", []string{"source", "database", "sink"}, sections)
+	}
+
+	if err := c.RenameSection("DB", "db"); err != NoSectionError("DB") {
+		t.Errorf("expected error %v, got %v", NoSectionError("DB"), err)
+	}
+	if err := c.RenameSection("database", "sink"); err != SectionExistsError("sink") {
+		t.Errorf("expected error %v, got %v", SectionExistsError("sink"), err)
+	}
+	verifyNil(t, c.RenameSection("sink", "sink"))
+	verifyEqual(t, c.Raw(), map[string]map[string]string{
+		"source":   {"path": "/in"},
+		"database": {"host": "localhost"},
+		"sink":     {"path": "/out"},
+	})
+}
+
+func TestRemove(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db":    {"password": "old-secret", "empty": ""},
+		"cache": {"ttl": "1m"},
+	})
+
+	tt := []struct {
+		name, section, key, expected string
+		ok                           bool
+	}{
+		{"existing key", "db", "password", "old-secret", true},
+		{"removed key", "db", "password", "", false},
+		{"empty value", "db", "empty", "", true},
+		{"missing section", "unknown", "password", "", false},
+		{"section", "cache", "", "", true},
+	}
+
+	for _, tc := range tt {
+		value, ok := c.Remove(tc.section, tc.key)
+		if ok != tc.ok || value != tc.expected {
+			t.Errorf("%s: expected %q, %t, got %q, %t", tc.name, tc.expected, tc.ok, value, ok)
+		}
+	}
+	verifyEqual(t, c.Raw(), map[string]map[string]string{"db": {}})
+}
+
+func TestPrune(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"Hero":     {"name": "Peter Parker"},
+		"Enemy":    {"name": "Harry Osborne"},
+		"Sidekick": {},
+	})
+	c.Unset("Enemy", "name")
+
+	if sections := c.EmptySections(); !reflect.DeepEqual(sections, []string{"Enemy", "Sidekick"}) {
+		t.Errorf("expected empty sections %v, got %v", []string{"Enemy", "Sidekick"}, sections)
+	}
+	if removed := c.Prune(); removed != 2 {
+		t.Errorf("expected 2 sections to be removed, got %d", removed)
+	}
+	verifyEqual(t, c.Raw(), map[string]map[string]string{"Hero": {"name": "Peter Parker"}})
+	if sections := c.OrderedSections(); !reflect.DeepEqual(sections, []string{"Hero"}) {
+		t.Errorf("expected ordered sections %v, got %v", []string{"Hero"}, sections)
+	}
+	if sections := c.EmptySections(); len(sections) != 0 {
+		t.Errorf("expected no empty sections, got %v", sections)
+	}
+	if removed := c.Prune(); removed != 0 {
+		t.Errorf("expected no sections to be removed, got %d", removed)
+	}
+}
+
 func TestConcurrency(t *testing.T) {
 	c := New(map[string]map[string]string{"Hero": {
 		"name":           "Peter Parker",
@@ -727,6 +2131,23 @@ func TestConcurrency(t *testing.T) {
 	wg.Wait()
 }
 
+func TestSnapshotIsolation(t *testing.T) {
+	params := map[string]map[string]string{"Hero": {"name": "Peter Parker", "alias": "Spiderman"}}
+	c := New(params)
+	snapshot := c.Raw()
+
+	// Modifying the pool must not affect earlier snapshots.
+	verifyNil(t, c.Set("Hero", "name", "Miles Morales"))
+	c.Unset("Hero", "alias")
+	verifyEqual(t, snapshot, map[string]map[string]string{"Hero": {"name": "Peter Parker", "alias": "Spiderman"}})
+
+	// Modifying the parameters given to New must not affect the pool.
+	params["Hero"]["name"] = "Gwen Stacy"
+	if name, _ := c.Get("Hero", "name"); name != "Miles Morales" {
+		t.Errorf("expected name to equal %q, got %q", "Miles Morales", name)
+	}
+}
+
 func TestMergeOverwriteStrategy(t *testing.T) {
 	tt := map[string]struct {
 		first    map[string]map[string]string
@@ -987,6 +2408,306 @@ func TestMergeReportStrategy(t *testing.T) {
 	}
 }
 
+func TestRebase(t *testing.T) {
+	defaults := New(map[string]map[string]string{
+		"db":    {"host": "localhost", "port": "3306", "timeout": "5s"},
+		"cache": {"ttl": "1m"},
+	})
+	user := New(map[string]map[string]string{
+		"db":  {"host": "db.example.com", "user": "admin"},
+		"app": {"name": "movies"},
+	})
+
+	rebased := user.Rebase(defaults)
+	verifyEqual(t, map[string]map[string]string{
+		"db":    {"host": "db.example.com", "port": "3306", "timeout": "5s", "user": "admin"},
+		"cache": {"ttl": "1m"},
+		"app":   {"name": "movies"},
+	}, rebased.Raw())
+
+	// Neither pool should be affected by the rebase, nor by changes to the rebased pool.
+	verifyNil(t, rebased.Set("cache", "ttl", "2m"))
+	verifyEqual(t, map[string]map[string]string{
+		"db":    {"host": "localhost", "port": "3306", "timeout": "5s"},
+		"cache": {"ttl": "1m"},
+	}, defaults.Raw())
+	verifyEqual(t, map[string]map[string]string{
+		"db":  {"host": "db.example.com", "user": "admin"},
+		"app": {"name": "movies"},
+	}, user.Raw())
+
+	// Rebasing onto an empty pool yields a copy.
+	verifyEqual(t, user.Raw(), user.Rebase(New(empty)).Raw())
+}
+
+func TestApplyOverlay(t *testing.T) {
+	params := map[string]map[string]string{
+		"db":           {"host": "localhost", "port": "3306"},
+		"db@prod":      {"host": "db.prod.example.com"},
+		"db@staging":   {"host": "db.staging.example.com"},
+		"cache":        {"ttl": "1m"},
+		"metrics":      {"enabled": "false"},
+		"metrics@prod": {"enabled": "true"},
+		"user@host":    {"name": "admin"},
+	}
+
+	tt := map[string]struct {
+		selector, sep string
+		expected      map[string]map[string]string
+	}{
+		"prod": {
+			"prod", "@", map[string]map[string]string{
+				"db":        {"host": "db.prod.example.com", "port": "3306"},
+				"cache":     {"ttl": "1m"},
+				"metrics":   {"enabled": "true"},
+				"user@host": {"name": "admin"},
+			},
+		},
+		"staging": {
+			"staging", "@", map[string]map[string]string{
+				"db":        {"host": "db.staging.example.com", "port": "3306"},
+				"cache":     {"ttl": "1m"},
+				"metrics":   {"enabled": "false"},
+				"user@host": {"name": "admin"},
+			},
+		},
+		"unknown selector": {
+			"dev", "@", map[string]map[string]string{
+				"db":        {"host": "localhost", "port": "3306"},
+				"cache":     {"ttl": "1m"},
+				"metrics":   {"enabled": "false"},
+				"user@host": {"name": "admin"},
+			},
+		},
+		"no base section": {
+			"host", "@", map[string]map[string]string{
+				"db":        {"host": "localhost", "port": "3306"},
+				"cache":     {"ttl": "1m"},
+				"metrics":   {"enabled": "false"},
+				"user@host": {"name": "admin"},
+			},
+		},
+		"empty separator": {
+			"prod", "", params,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			c := New(params)
+			actual := c.ApplyOverlay(tc.selector, tc.sep)
+			verifyEqual(t, tc.expected, actual.Raw())
+			verifyEqual(t, params, c.Raw())
+		})
+	}
+}
+
+func TestMergeWith(t *testing.T) {
+	longest := func(section, key, existing, incoming string) (string, error) {
+		if len(incoming) > len(existing) {
+			return incoming, nil
+		}
+		return existing, nil
+	}
+
+	c := New(map[string]map[string]string{
+		"acl": {"allow": "10.0.0.1,10.0.0.2", "deny": "10.0.0.3"},
+	})
+	verifyNil(t, c.MergeWith(map[string]map[string]string{
+		"acl":   {"allow": "10.0.0.4", "deny": "10.0.0.3,10.0.0.5", "log": "true"},
+		"cache": {"ttl": "1m"},
+	}, longest))
+	verifyEqual(t, map[string]map[string]string{
+		"acl":   {"allow": "10.0.0.1,10.0.0.2", "deny": "10.0.0.3,10.0.0.5", "log": "true"},
+		"cache": {"ttl": "1m"},
+	}, c.Raw())
+
+	errSum := errors.New("not a number")
+	sum := func(section, key, existing, incoming string) (string, error) {
+		a, errA := strconv.Atoi(existing)
+		b, errB := strconv.Atoi(incoming)
+		if errA != nil || errB != nil {
+			return "", errSum
+		}
+		return strconv.Itoa(a + b), nil
+	}
+
+	c = New(map[string]map[string]string{"limits": {"a": "1", "b": "x", "c": "3"}})
+	err := c.MergeWith(map[string]map[string]string{"limits": {"a": "2", "b": "2", "c": "4"}}, sum)
+	if !errors.Is(err, errSum) || !strings.Contains(err.Error(), `key "b"`) {
+		t.Errorf("expected resolver error for key b, got %v", err)
+	}
+	verifyEqual(t, map[string]map[string]string{"limits": {"a": "1", "b": "x", "c": "3"}}, c.Raw())
+
+	verifyNil(t, c.MergeWith(map[string]map[string]string{"limits": {"a": "2", "c": "4"}}, sum))
+	verifyEqual(t, map[string]map[string]string{"limits": {"a": "3", "b": "x", "c": "7"}}, c.Raw())
+}
+
+func TestMergeReport(t *testing.T) {
+	params := map[string]map[string]string{
+		"db":    {"host": "localhost", "port": "3306"},
+		"cache": {"ttl": "1m"},
+	}
+	c := New(copyParams(params))
+
+	err := c.MergeReport(map[string]map[string]string{
+		"db":    {"host": "db.example.com", "port": "3306", "user": "root"},
+		"cache": {"ttl": "5m"},
+		"api":   {"url": "https://example.com"},
+	})
+	var conflictErr MergeConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected MergeConflictError, got %v", err)
+	}
+	expected := []Conflict{
+		{Section: "cache", Key: "ttl", Mine: "1m", Theirs: "5m"},
+		{Section: "db", Key: "host", Mine: "localhost", Theirs: "db.example.com"},
+		{Section: "db", Key: "port", Mine: "3306", Theirs: "3306"},
+	}
+	if actual := conflictErr.Conflicts(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected conflicts %v, got %v", expected, actual)
+	}
+	expectedMsg := `section "cache", key "ttl" already exists; section "db", key "host" already exists; ` +
+		`section "db", key "port" already exists`
+	if err.Error() != expectedMsg {
+		t.Errorf("expected error %q, got %q", expectedMsg, err.Error())
+	}
+	verifyEqual(t, params, c.Raw())
+
+	verifyNil(t, c.MergeReport(map[string]map[string]string{"db": {"user": "root"}, "api": {"url": "https://example.com"}}))
+	verifyEqual(t, map[string]map[string]string{
+		"db":    {"host": "localhost", "port": "3306", "user": "root"},
+		"cache": {"ttl": "1m"},
+		"api":   {"url": "https://example.com"},
+	}, c.Raw())
+}
+
+func TestMergePreview(t *testing.T) {
+	params := map[string]map[string]string{
+		"db": {"host": "localhost", "port": "3306"},
+	}
+	c := New(copyParams(params))
+
+	override := map[string]map[string]string{
+		"db":    {"host": "db.example.com"},
+		"cache": {"ttl": "1m"},
+	}
+
+	preview, err := c.MergePreview(override, Overwrite)
+	verifyNil(t, err)
+	verifyEqual(t, map[string]map[string]string{
+		"db":    {"host": "db.example.com", "port": "3306"},
+		"cache": {"ttl": "1m"},
+	}, preview.Raw())
+	verifyEqual(t, params, c.Raw())
+	verifyEqual(t, map[string]map[string]string{"db": {"host": "db.example.com"}, "cache": {"ttl": "1m"}}, c.Compare(preview))
+
+	// Modifying the preview must not affect the pool or the given parameters.
+	verifyNil(t, preview.Set("cache", "ttl", "2m"))
+	if override["cache"]["ttl"] != "1m" {
+		t.Error("expected merged parameters to be unaffected by changes to the preview")
+	}
+
+	if _, err = c.MergePreview(override, Report); err == nil {
+		t.Error("expected conflict error for Report strategy")
+	}
+	verifyEqual(t, params, c.Raw())
+}
+
+func TestMergeProtecting(t *testing.T) {
+	reloaded := map[string]map[string]string{
+		"node":  {"id": "from-file", "name": "worker", "zone": "eu"},
+		"cache": {"ttl": "1m"},
+	}
+	protected := map[string][]string{"node": {"id", "zone"}, "unknown": {"key"}}
+
+	c := New(map[string]map[string]string{"node": {"id": "generated", "name": "old"}})
+	verifyNil(t, c.MergeProtecting(reloaded, protected, Overwrite))
+	verifyEqual(t, map[string]map[string]string{
+		"node":  {"id": "generated", "name": "worker", "zone": "eu"},
+		"cache": {"ttl": "1m"},
+	}, c.Raw())
+	if reloaded["node"]["id"] != "from-file" {
+		t.Error("expected merged parameters to be unaffected by protection")
+	}
+
+	// Protected keys don't cause conflicts with the Report strategy.
+	c = New(map[string]map[string]string{"node": {"id": "generated"}})
+	verifyNil(t, c.MergeProtecting(reloaded, protected, Report))
+	verifyEqual(t, map[string]map[string]string{
+		"node":  {"id": "generated", "name": "worker", "zone": "eu"},
+		"cache": {"ttl": "1m"},
+	}, c.Raw())
+
+	c = New(map[string]map[string]string{"node": {"id": "generated", "name": "old"}})
+	if err := c.MergeProtecting(reloaded, protected, Report); err == nil {
+		t.Error("expected conflict error for unprotected key with Report strategy")
+	}
+	verifyEqual(t, map[string]map[string]string{"node": {"id": "generated", "name": "old"}}, c.Raw())
+}
+
+func TestClone(t *testing.T) {
+	c := New(map[string]map[string]string{"source": {"path": "/in"}})
+	verifyNil(t, c.Merge(map[string]map[string]string{"sink": {"path": "/out"}}, Report))
+
+	clone := c.Clone()
+	verifyEqual(t, c.Raw(), clone.Raw())
+	if sections := clone.OrderedSections(); !reflect.DeepEqual(sections, []string{"source", "sink"}) {
+		t.Errorf("expected ordered sections %v, got %v", []string{"source", "sink"}, sections)
+	}
+
+	verifyNil(t, clone.Set("source", "path", "/tmp"))
+	clone.Unset("sink", "")
+	verifyNil(t, clone.Merge(map[string]map[string]string{"extra": {"key": "value"}}, Report))
+	verifyEqual(t, c.Raw(), map[string]map[string]string{"source": {"path": "/in"}, "sink": {"path": "/out"}})
+
+	verifyNil(t, c.Set("source", "format", "csv"))
+	verifyEqual(t, clone.Raw(), map[string]map[string]string{"source": {"path": "/tmp"}, "extra": {"key": "value"}})
+}
+
+func TestSectionNames(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"prod":  {"db.host": "db.example.com"},
+		"dev":   {"db.host": "localhost"},
+		"empty": {},
+	})
+	expected := []string{"dev", "empty", "prod"}
+	if actual := c.SectionNames(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected section names %v, got %v", expected, actual)
+	}
+	if actual := New(map[string]map[string]string{}).SectionNames(); len(actual) != 0 {
+		t.Errorf("expected no section names, got %v", actual)
+	}
+}
+
+func TestOrderedSections(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"source":    {"path": "/in"},
+		"transform": {"op": "upper"},
+	})
+	verifyNil(t, c.Merge(map[string]map[string]string{"sink": {"path": "/out"}}, Report))
+	verifyNil(t, c.Merge(map[string]map[string]string{"filter": {"expr": "x"}, "buffer": {"size": "4"}}, Report))
+	verifyNil(t, c.Merge(map[string]map[string]string{"source": {"format": "csv"}}, Report))
+
+	expected := []string{"source", "transform", "sink", "buffer", "filter"}
+	if actual := c.OrderedSections(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected sections %v, got %v", expected, actual)
+	}
+
+	c.Unset("transform", "")
+	verifyNil(t, c.Merge(map[string]map[string]string{"transform": {"op": "lower"}}, Report))
+	expected = []string{"source", "sink", "buffer", "filter", "transform"}
+	if actual := c.OrderedSections(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected sections %v, got %v", expected, actual)
+	}
+
+	// Derived pools fall back to alphabetical order.
+	expected = []string{"buffer", "filter", "sink", "source", "transform"}
+	if actual := c.Rebase(New(empty)).OrderedSections(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected sections %v, got %v", expected, actual)
+	}
+}
+
 func TestCompare(t *testing.T) {
 	tt := map[string]struct {
 		p1, p2, expected map[string]map[string]string
@@ -1049,6 +2770,229 @@ func TestCompare(t *testing.T) {
 	}
 }
 
+func TestRequireSections(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db":    {"host": "localhost"},
+		"empty": {},
+	})
+
+	tt := map[string]struct {
+		names    []string
+		expected error
+	}{
+		"no sections":      {nil, nil},
+		"all present":      {[]string{"db", "empty"}, nil},
+		"missing sections": {[]string{"cache", "db", "queue"}, MultiError{NoSectionError("cache"), NoSectionError("queue")}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := c.RequireSections(tc.names...)
+			if !reflect.DeepEqual(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateNoUnresolved(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"api": {
+			"url":   "https://${hsot}:${port}/",
+			"plain": "$HOME and {braces}",
+		},
+		"db": {
+			"host": "${db_host}",
+			"port": "3306",
+		},
+	})
+
+	err := c.ValidateNoUnresolved()
+	expected := MultiError{
+		UnresolvedTokenError{"api", "url", "${hsot}"},
+		UnresolvedTokenError{"api", "url", "${port}"},
+		UnresolvedTokenError{"db", "host", "${db_host}"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+
+	verifyNil(t, New(map[string]map[string]string{"api": {"plain": "$HOME"}}).ValidateNoUnresolved())
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("CONFIGURAMA_HOST", "db.example.com")
+	t.Setenv("CONFIGURAMA_EMPTY", "")
+	params := map[string]map[string]string{
+		"db": {
+			"host":     "${CONFIGURAMA_HOST}",
+			"url":      "mysql://$CONFIGURAMA_HOST:${CONFIGURAMA_PORT:-3306}/app",
+			"user":     "${CONFIGURAMA_UNSET}",
+			"password": "${CONFIGURAMA_EMPTY:-secret}",
+			"plain":    "no variables",
+		},
+		"cache": {"ttl": "1m"},
+	}
+	c := New(params)
+
+	c.ExpandEnv()
+	verifyEqual(t, c.Raw(), map[string]map[string]string{
+		"db": {
+			"host":     "db.example.com",
+			"url":      "mysql://db.example.com:3306/app",
+			"user":     "",
+			"password": "secret",
+			"plain":    "no variables",
+		},
+		"cache": {"ttl": "1m"},
+	})
+	if params["db"]["host"] != "${CONFIGURAMA_HOST}" {
+		t.Error("expected given parameters to be unaffected by expansion")
+	}
+}
+
+func TestForEachKey(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db": {
+			"port": "3306",
+			"host": "",
+		},
+		"cache": {
+			"host": "",
+		},
+	})
+
+	visited := make([]string, 0)
+	err := c.ForEachKey(func(section, key, value string) error {
+		visited = append(visited, section+"."+key)
+		if value == "" {
+			return fmt.Errorf("%s.%s is empty", section, key)
+		}
+		return nil
+	})
+
+	if expected := []string{"cache.host", "db.host", "db.port"}; !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected keys %v to be visited, got %v", expected, visited)
+	}
+	var multiErr MultiError
+	if !errors.As(err, &multiErr) || len(multiErr) != 2 {
+		t.Fatalf("expected MultiError with 2 errors, got %v", err)
+	}
+	if err.Error() != "cache.host is empty; db.host is empty" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	verifyNil(t, c.ForEachKey(func(section, key, value string) error { return nil }))
+}
+
+func TestEach(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db":    {"port": "3306", "host": "localhost"},
+		"cache": {"ttl": "1m"},
+		"empty": {},
+	})
+
+	visited := make([]string, 0)
+	c.Each(func(section, key, value string) bool {
+		visited = append(visited, section+"."+key+"="+value)
+		return true
+	})
+	expected := []string{"cache.ttl=1m", "db.host=localhost", "db.port=3306"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v, got %v", expected, visited)
+	}
+
+	visited = visited[:0]
+	c.Each(func(section, key, value string) bool {
+		visited = append(visited, section+"."+key)
+		verifyNil(t, c.Set("db", "user", "root")) // Not visited, since Each iterates over a snapshot.
+		return key != "host"
+	})
+	if expected = []string{"cache.ttl", "db.host"}; !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v, got %v", expected, visited)
+	}
+}
+
+func TestCompareSections(t *testing.T) {
+	p1 := New(map[string]map[string]string{
+		"db":    {"host": "localhost", "port": "3306"},
+		"cache": {"ttl": "5m"},
+		"queue": {"size": "10"},
+	})
+	p2 := New(map[string]map[string]string{
+		"db":    {"host": "db.local", "port": "3306"},
+		"cache": {"ttl": "10m"},
+		"queue": {"size": "20"},
+		"new":   {"key": "val"},
+	})
+
+	verifyEqual(t, p1.CompareSections(p2, "db", "new", "unknown"), map[string]map[string]string{
+		"db":  {"host": "db.local"},
+		"new": {"key": "val"},
+	})
+	verifyEqual(t, p1.CompareSections(p2), empty)
+	verifyEqual(t, p2.CompareSections(p1, "db", "new"), map[string]map[string]string{"db": {"host": "localhost"}})
+}
+
+func TestRedundantKeys(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"default": {"host": "localhost", "port": "3306", "timeout": "5s"},
+		"mysql":   {"host": "localhost", "port": "3307", "user": "root"},
+		"mariadb": {"host": "db.example.com", "port": "3306", "timeout": "5s"},
+		"cache":   {"ttl": "1m"},
+	})
+
+	verifyEqual(t, map[string]map[string]string{
+		"mysql":   {"host": "localhost"},
+		"mariadb": {"port": "3306", "timeout": "5s"},
+	}, c.RedundantKeys("default"))
+
+	verifyEqual(t, empty, c.RedundantKeys("unknown"))
+}
+
+func TestValidateInSectionKeys(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"app": {
+			"defaultProfile":  "fast",
+			"fallbackProfile": "unknown",
+		},
+		"profiles": {
+			"fast": "1",
+			"slow": "2",
+		},
+	})
+
+	tt := map[string]struct {
+		section, key, allowSection string
+		expected                   error
+	}{
+		"valid reference": {
+			"app", "defaultProfile", "profiles", nil,
+		},
+		"invalid reference": {
+			"app", "fallbackProfile", "profiles", ReferenceError{"fallbackProfile", "unknown", `key in section "profiles"`},
+		},
+		"unknown key": {
+			"app", "unknown", "profiles", NoKeyError("unknown"),
+		},
+		"unknown section": {
+			"unknown", "defaultProfile", "profiles", NoSectionError("unknown"),
+		},
+		"unknown allow section": {
+			"app", "defaultProfile", "unknown", NoSectionError("unknown"),
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := c.ValidateInSectionKeys(tc.section, tc.key, tc.allowSection)
+			if err != tc.expected {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
 func TestMustPrettyPrint(t *testing.T) {
 	tt := map[string]struct {
 		config   map[string]map[string]string
@@ -1114,26 +3058,126 @@ func TestMustPrettyPrint(t *testing.T) {
 			if actual != tc.expected {
 				t.Errorf("expected output to match %q, got %q", tc.expected, actual)
 			}
+
+			actual, err := PrettyPrint(tc.config, "  ")
+			verifyNil(t, err)
+			if actual != tc.expected {
+				t.Errorf("expected PrettyPrint output to match %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMustPrettyPrintQuote(t *testing.T) {
+	config := map[string]map[string]string{
+		"Params one": {
+			"plain":    "Value one",
+			"empty":    "",
+			"padded":   " Value two ",
+			"newline":  "Value\nthree",
+			"quoted":   `"Value four"`,
+			"embedded": `Value "five"`,
+		},
+	}
+
+	tt := map[string]struct {
+		policy   QuotePolicy
+		expected string
+	}{
+		"quote never": {
+			policy: QuoteNever,
+			expected: `[Params one]
+  embedded: Value "five"
+  empty: 
+  newline: Value
+three
+  padded:  Value two 
+  plain: Value one
+  quoted: "Value four"`,
+		},
+		"quote ambiguous": {
+			policy: QuoteAmbiguous,
+			expected: `[Params one]
+  embedded: Value "five"
+  empty: 
+  newline: "Value\nthree"
+  padded: " Value two "
+  plain: Value one
+  quoted: "\"Value four\""`,
+		},
+		"quote always": {
+			policy: QuoteAlways,
+			expected: `[Params one]
+  embedded: "Value \"five\""
+  empty: ""
+  newline: "Value\nthree"
+  padded: " Value two "
+  plain: "Value one"
+  quoted: "\"Value four\""`,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual := MustPrettyPrint(config, "  ", Quote(tc.policy))
+			if actual != tc.expected {
+				t.Errorf("expected output to match %q, got %q", tc.expected, actual)
+			}
 		})
 	}
 }
 
+func TestPrettyPrintMasked(t *testing.T) {
+	params := map[string]map[string]string{
+		"db": {
+			"host":       "localhost",
+			"password":   "secret",
+			"DBPassword": "secret",
+			"motd":       " padded ",
+		},
+		"api": {
+			"api_secret": "abc123",
+			"url":        "https://example.com",
+		},
+	}
+	c := New(params)
+
+	expected := `[api]
+  api_secret: ****
+  url: https://example.com
+
+[db]
+  DBPassword: ****
+  host: localhost
+  motd: " padded "
+  password: ****`
+	if actual := c.PrettyPrintMasked("  ", []string{"PASSWORD", "secret", ""}, Quote(QuoteAmbiguous)); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if actual, expected := c.PrettyPrintMasked("  ", nil), MustPrettyPrint(params, "  "); actual != expected {
+		t.Errorf("expected output identical to MustPrettyPrint %q, got %q", expected, actual)
+	}
+	verifyEqual(t, params, c.Raw())
+}
+
 func TestErrors(t *testing.T) {
-	var actual error
+	var convErr ConversionError
+	var noKeyErr NoKeyError
+	var validErr RegExpValidationError
 	var ok bool
 
 	errConv := ConversionError{"x", "y", "Int"}
-	if ok = errors.As(errConv, &actual); !ok {
+	if ok = errors.As(errConv, &convErr); !ok {
 		t.Errorf("expected to be able to match ConversionError")
 	}
 
 	errNoKey := NoKeyError("x")
-	if ok = errors.As(errNoKey, &actual); !ok {
+	if ok = errors.As(errNoKey, &noKeyErr); !ok {
 		t.Errorf("expected to be able to match NoKeyError")
 	}
 
 	errValid := RegExpValidationError("x")
-	if ok = errors.As(errValid, &actual); !ok {
+	if ok = errors.As(errValid, &validErr); !ok {
 		t.Errorf("expected to be able to match RegExpValidationError")
 	}
 }
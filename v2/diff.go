@@ -0,0 +1,122 @@
+package configurama
+
+// Change represents a changed value.
+type Change struct {
+	Old, New string
+}
+
+// SectionDiff represents the differences between two versions of a section.
+// Added and Removed contain the keys and values that are only present in the new and old version, respectively,
+// while Changed contains the keys whose values differ between the two versions.
+type SectionDiff struct {
+	Added   map[string]string
+	Removed map[string]string
+	Changed map[string]Change
+}
+
+// Empty returns true if there are no differences.
+func (d SectionDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff represents the differences between two versions of a configuration pool, grouped by section.
+// Only sections with at least one added, removed or changed key are included.
+type Diff map[string]SectionDiff
+
+// Empty returns true if there are no differences.
+func (d Diff) Empty() bool {
+	return len(d) == 0
+}
+
+// ReloadFrom replaces the entire contents of the pool with the given parameters, and returns the differences
+// between the previous and the new contents. The diff is computed and the contents replaced under the same lock,
+// so the returned diff describes exactly the change made, even with concurrent writers. This is meant to be
+// called by file watchers or admin endpoints that reload the configuration and need to act on what changed.
+// Sections not present in params are removed, and the recorded sources of changed keys are cleared if provenance
+// is enabled. Modifying params afterwards will not affect the configuration pool.
+func (p *Pool) ReloadFrom(params map[string]map[string]string) Diff {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := p.load()
+	res := copyParams(params)
+	d := Diff(diffBySection(current, res))
+	p.store(res)
+
+	order := make([]string, 0, len(res))
+	for _, sec := range p.order {
+		if _, ok := res[sec]; ok {
+			order = append(order, sec)
+		}
+	}
+	p.order = appendNewSections(order, current, res)
+
+	for sec, sd := range d {
+		for key := range sd.Added {
+			p.recordSource(sec, key, "")
+		}
+		for key := range sd.Removed {
+			p.recordSource(sec, key, "")
+		}
+		for key := range sd.Changed {
+			p.recordSource(sec, key, "")
+		}
+	}
+	return d
+}
+
+// DiffBySection returns the differences between the pool that DiffBySection is called from (the old version) and
+// the given pool (the new version), grouped by section. Only sections with at least one added, removed or changed
+// key are included, so sections that are identical, or empty in both versions, are left out.
+// This makes it easy to dispatch changes to the subsystem responsible for each section.
+func (p *Pool) DiffBySection(other *Pool) map[string]SectionDiff {
+	return diffBySection(p.load(), other.load())
+}
+
+// CompareDetailed returns the differences between the pool that CompareDetailed is called from (the old version)
+// and the given pool (the new version). Unlike Compare, added keys are told apart from changed values, and removed
+// keys are included as well, which is useful for reporting configuration drift. The differences are grouped by
+// section, the same way as by DiffBySection.
+func (p *Pool) CompareDetailed(other *Pool) Diff {
+	return Diff(p.DiffBySection(other))
+}
+
+// diffBySection returns the differences between the old and new parameters, grouped by section.
+func diffBySection(old, new map[string]map[string]string) map[string]SectionDiff {
+	res := make(map[string]SectionDiff)
+
+	sections := make(map[string]bool, len(old)+len(new))
+	for sec := range old {
+		sections[sec] = true
+	}
+	for sec := range new {
+		sections[sec] = true
+	}
+
+	for sec := range sections {
+		d := SectionDiff{
+			Added:   make(map[string]string),
+			Removed: make(map[string]string),
+			Changed: make(map[string]Change),
+		}
+		for key, val := range new[sec] {
+			oldVal, ok := old[sec][key]
+			switch {
+			case !ok:
+				d.Added[key] = val
+			case oldVal != val:
+				d.Changed[key] = Change{oldVal, val}
+			}
+		}
+		for key, val := range old[sec] {
+			if _, ok := new[sec][key]; !ok {
+				d.Removed[key] = val
+			}
+		}
+		if !d.Empty() {
+			res[sec] = d
+		}
+	}
+
+	return res
+}
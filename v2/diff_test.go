@@ -0,0 +1,131 @@
+package configurama
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffBySection(t *testing.T) {
+	old := New(map[string]map[string]string{
+		"db":     {"host": "localhost", "port": "3306", "user": "root"},
+		"cache":  {"ttl": "1m"},
+		"legacy": {"enabled": "true"},
+		"empty":  {},
+	})
+	new := New(map[string]map[string]string{
+		"db":      {"host": "db.example.com", "port": "3306", "password": "secret"},
+		"cache":   {"ttl": "1m"},
+		"metrics": {"enabled": "true"},
+		"empty":   {},
+	})
+
+	expected := map[string]SectionDiff{
+		"db": {
+			Added:   map[string]string{"password": "secret"},
+			Removed: map[string]string{"user": "root"},
+			Changed: map[string]Change{"host": {"localhost", "db.example.com"}},
+		},
+		"legacy": {
+			Added:   map[string]string{},
+			Removed: map[string]string{"enabled": "true"},
+			Changed: map[string]Change{},
+		},
+		"metrics": {
+			Added:   map[string]string{"enabled": "true"},
+			Removed: map[string]string{},
+			Changed: map[string]Change{},
+		},
+	}
+
+	actual := old.DiffBySection(new)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected diff %v, got %v", expected, actual)
+	}
+
+	if actual = old.DiffBySection(old); len(actual) != 0 {
+		t.Errorf("expected no differences for identical pools, got %v", actual)
+	}
+}
+
+func TestCompareDetailed(t *testing.T) {
+	old := New(map[string]map[string]string{
+		"db":     {"host": "localhost", "user": "root"},
+		"legacy": {"enabled": "true"},
+	})
+	new := New(map[string]map[string]string{
+		"db": {"host": "db.example.com", "password": "secret"},
+	})
+
+	expected := Diff{
+		"db": {
+			Added:   map[string]string{"password": "secret"},
+			Removed: map[string]string{"user": "root"},
+			Changed: map[string]Change{"host": {"localhost", "db.example.com"}},
+		},
+		"legacy": {
+			Added:   map[string]string{},
+			Removed: map[string]string{"enabled": "true"},
+			Changed: map[string]Change{},
+		},
+	}
+
+	actual := old.CompareDetailed(new)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected diff %v, got %v", expected, actual)
+	}
+	if actual = new.CompareDetailed(new); !actual.Empty() {
+		t.Errorf("expected no differences for identical pools, got %v", actual)
+	}
+}
+
+func TestReloadFrom(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db":     {"host": "localhost", "port": "3306"},
+		"legacy": {"enabled": "true"},
+	})
+	c.EnableProvenance()
+	verifyNil(t, c.MergeFrom("file", map[string]map[string]string{"db": {"host": "db.local"}}, Overwrite))
+
+	params := map[string]map[string]string{
+		"db":      {"host": "db.example.com", "port": "3306"},
+		"metrics": {"enabled": "true"},
+	}
+	expected := Diff{
+		"db": {
+			Added:   map[string]string{},
+			Removed: map[string]string{},
+			Changed: map[string]Change{"host": {"db.local", "db.example.com"}},
+		},
+		"legacy": {
+			Added:   map[string]string{},
+			Removed: map[string]string{"enabled": "true"},
+			Changed: map[string]Change{},
+		},
+		"metrics": {
+			Added:   map[string]string{"enabled": "true"},
+			Removed: map[string]string{},
+			Changed: map[string]Change{},
+		},
+	}
+
+	actual := c.ReloadFrom(params)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected diff %v, got %v", expected, actual)
+	}
+	verifyEqual(t, c.Raw(), params)
+	if sections := c.OrderedSections(); !reflect.DeepEqual(sections, []string{"db", "metrics"}) {
+		t.Errorf("expected ordered sections %v, got %v", []string{"db", "metrics"}, sections)
+	}
+	if source, ok := c.Source("db", "host"); ok {
+		t.Errorf("expected source of reloaded key to be cleared, got %q", source)
+	}
+
+	params["db"]["host"] = "modified"
+	if host, _ := c.Get("db", "host"); host != "db.example.com" {
+		t.Errorf("expected pool to be unaffected by changes to the given parameters, got %q", host)
+	}
+
+	if d := c.ReloadFrom(c.Raw()); !d.Empty() {
+		t.Errorf("expected no differences when reloading identical parameters, got %v", d)
+	}
+}
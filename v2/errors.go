@@ -1,6 +1,9 @@
 package configurama
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // NoSectionError represents unknown sections.
 type NoSectionError string
@@ -10,6 +13,14 @@ func (s NoSectionError) Error() string {
 	return fmt.Sprintf("no such section: %q", string(s))
 }
 
+// SectionExistsError represents sections that already exist, but are not expected to.
+type SectionExistsError string
+
+// Error returns the error message for SectionExistsError.
+func (s SectionExistsError) Error() string {
+	return fmt.Sprintf("section already exists: %q", string(s))
+}
+
 // NoKeyError represents unknown keys when required via the Option Require.
 type NoKeyError string
 
@@ -18,6 +29,32 @@ func (k NoKeyError) Error() string {
 	return fmt.Sprintf("no such key: %q", string(k))
 }
 
+// ConditionalKeyError represents keys that are required because of the value of another key, but don't exist.
+type ConditionalKeyError struct {
+	key, condKey, condValue string
+}
+
+// Error returns the error message for ConditionalKeyError.
+func (c ConditionalKeyError) Error() string {
+	return fmt.Sprintf("no such key: %q, required when %q is %q", c.key, c.condKey, c.condValue)
+}
+
+// UnknownKeyError represents keys that are present, but not expected.
+type UnknownKeyError string
+
+// Error returns the error message for UnknownKeyError.
+func (u UnknownKeyError) Error() string {
+	return fmt.Sprintf("unknown key: %q", string(u))
+}
+
+// NoSnapshotError represents snapshots that don't exist.
+type NoSnapshotError string
+
+// Error returns the error message for NoSnapshotError.
+func (n NoSnapshotError) Error() string {
+	return fmt.Sprintf("no such snapshot: %q", string(n))
+}
+
 // RegExpValidationError represents an error with value validation against a regular expression.
 type RegExpValidationError string
 
@@ -34,6 +71,162 @@ func (e EnumValidationError) Error() string {
 	return fmt.Sprintf("enum validation failed for key: %q", string(e))
 }
 
+// ForbiddenValueError represents an error with value validation against a list of forbidden values.
+type ForbiddenValueError string
+
+// Error returns the error message for ForbiddenValueError.
+func (f ForbiddenValueError) Error() string {
+	return fmt.Sprintf("forbidden value for key: %q", string(f))
+}
+
+// SubsetValidationError represents an error with validation of a list element against a set of allowed values.
+type SubsetValidationError struct {
+	key, element string
+}
+
+// Error returns the error message for SubsetValidationError.
+func (s SubsetValidationError) Error() string {
+	return fmt.Sprintf("subset validation failed for key %q: element %q is not allowed", s.key, s.element)
+}
+
+// SchemaValidationError represents an error with value validation against a JSON Schema.
+type SchemaValidationError struct {
+	key, reason string
+}
+
+// Error returns the error message for SchemaValidationError.
+func (s SchemaValidationError) Error() string {
+	return fmt.Sprintf("schema validation failed for key %q: %s", s.key, s.reason)
+}
+
+// JSONFieldsValidationError represents an error with value validation of a JSON object's top-level fields.
+type JSONFieldsValidationError struct {
+	key, missing string
+}
+
+// Error returns the error message for JSONFieldsValidationError.
+func (j JSONFieldsValidationError) Error() string {
+	return fmt.Sprintf("JSON fields validation failed for key %q: missing fields %s", j.key, j.missing)
+}
+
+// PathValidationError represents an error with value validation of a path against a root directory.
+type PathValidationError string
+
+// Error returns the error message for PathValidationError.
+func (p PathValidationError) Error() string {
+	return fmt.Sprintf("path validation failed for key: %q", string(p))
+}
+
+// UUIDValidationError represents an error with value validation as a UUID.
+type UUIDValidationError string
+
+// Error returns the error message for UUIDValidationError.
+func (u UUIDValidationError) Error() string {
+	return fmt.Sprintf("UUID validation failed for key: %q", string(u))
+}
+
+// LanguageTagValidationError represents an error with value validation as a BCP 47 language tag.
+type LanguageTagValidationError string
+
+// Error returns the error message for LanguageTagValidationError.
+func (l LanguageTagValidationError) Error() string {
+	return fmt.Sprintf("language tag validation failed for key: %q", string(l))
+}
+
+// URLValidationError represents an error with value validation as an absolute URL.
+type URLValidationError string
+
+// Error returns the error message for URLValidationError.
+func (u URLValidationError) Error() string {
+	return fmt.Sprintf("URL validation failed for key: %q", string(u))
+}
+
+// IPValidationError represents an error with value validation as an IP address.
+type IPValidationError string
+
+// Error returns the error message for IPValidationError.
+func (i IPValidationError) Error() string {
+	return fmt.Sprintf("IP validation failed for key: %q", string(i))
+}
+
+// PortValidationError represents an error with value validation as a port number.
+type PortValidationError string
+
+// Error returns the error message for PortValidationError.
+func (p PortValidationError) Error() string {
+	return fmt.Sprintf("port validation failed for key: %q", string(p))
+}
+
+// PowerOfTwoValidationError represents an error with value validation as a power of two.
+type PowerOfTwoValidationError string
+
+// Error returns the error message for PowerOfTwoValidationError.
+func (p PowerOfTwoValidationError) Error() string {
+	return fmt.Sprintf("power of two validation failed for key: %q", string(p))
+}
+
+// SignValidationError represents an error with value validation as a positive or non-negative number.
+type SignValidationError struct {
+	key, value, sign string
+}
+
+// Error returns the error message for SignValidationError.
+func (s SignValidationError) Error() string {
+	return fmt.Sprintf("sign validation failed for key %q: value %s is not %s", s.key, s.value, s.sign)
+}
+
+// LengthValidationError represents an error with value validation of the number of characters in a value.
+type LengthValidationError struct {
+	key              string
+	length, min, max int
+}
+
+// Error returns the error message for LengthValidationError.
+func (l LengthValidationError) Error() string {
+	if l.max > 0 {
+		return fmt.Sprintf("length validation failed for key %q: length %d is not between %d and %d", l.key, l.length, l.min, l.max)
+	}
+	return fmt.Sprintf("length validation failed for key %q: length %d is less than %d", l.key, l.length, l.min)
+}
+
+// ChecksumValidationError represents an error with value validation against an embedded checksum.
+type ChecksumValidationError struct {
+	key, reason string
+}
+
+// Error returns the error message for ChecksumValidationError.
+func (c ChecksumValidationError) Error() string {
+	return fmt.Sprintf("checksum validation failed for key %q: %s", c.key, c.reason)
+}
+
+// ResolveValidationError represents an error with value validation as a resolvable host name.
+type ResolveValidationError struct {
+	key, reason string
+}
+
+// Error returns the error message for ResolveValidationError.
+func (r ResolveValidationError) Error() string {
+	return fmt.Sprintf("host name validation failed for key %q: %s", r.key, r.reason)
+}
+
+// CommandValidationError represents an error with value validation via an external command.
+type CommandValidationError struct {
+	key, command, reason string
+}
+
+// Error returns the error message for CommandValidationError.
+func (c CommandValidationError) Error() string {
+	return fmt.Sprintf("command %q validation failed for key %q: %s", c.command, c.key, c.reason)
+}
+
+// ZeroValueError represents numeric values that are zero when required to be non-zero via the Option NonZero.
+type ZeroValueError string
+
+// Error returns the error message for ZeroValueError.
+func (z ZeroValueError) Error() string {
+	return fmt.Sprintf("zero value for non-zero key: %q", string(z))
+}
+
 // ConversionError represents keys and values that can't be converted into the desired type.
 type ConversionError struct {
 	key, value, datatype string
@@ -43,3 +236,85 @@ type ConversionError struct {
 func (c ConversionError) Error() string {
 	return fmt.Sprintf("unable to convert value %q for key %q into %s", c.value, c.key, c.datatype)
 }
+
+// ReferenceError represents values that are expected to refer to an existing section or key, but don't.
+type ReferenceError struct {
+	key, value, target string
+}
+
+// Error returns the error message for ReferenceError.
+func (r ReferenceError) Error() string {
+	return fmt.Sprintf("value %q for key %q does not refer to an existing %s", r.value, r.key, r.target)
+}
+
+// UnresolvedTokenError represents an interpolation token that hasn't been resolved.
+type UnresolvedTokenError struct {
+	section, key, token string
+}
+
+// Error returns the error message for UnresolvedTokenError.
+func (u UnresolvedTokenError) Error() string {
+	return fmt.Sprintf("unresolved token %s in section %q, key %q", u.token, u.section, u.key)
+}
+
+// SecretError represents a secret reference that couldn't be resolved. The error returned by the secret resolver
+// can be retrieved via errors.Unwrap.
+type SecretError struct {
+	key, ref string
+	err      error
+}
+
+// Error returns the error message for SecretError.
+func (s SecretError) Error() string {
+	return fmt.Sprintf("unable to resolve secret %q for key %q: %v", s.ref, s.key, s.err)
+}
+
+// Unwrap returns the error returned by the secret resolver.
+func (s SecretError) Unwrap() error {
+	return s.err
+}
+
+// MergeConflictError represents the conflicting keys found when merging with MergeReport.
+type MergeConflictError struct {
+	conflicts []Conflict
+}
+
+// Error returns the error message for MergeConflictError, which lists all the conflicting keys.
+func (m MergeConflictError) Error() string {
+	msgs := make([]string, 0, len(m.conflicts))
+	for _, c := range m.conflicts {
+		msgs = append(msgs, fmt.Sprintf("section %q, key %q already exists", c.Section, c.Key))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Conflicts returns the conflicting keys, sorted by section and key. Mine is the existing value, and Theirs the
+// value that was to be merged.
+func (m MergeConflictError) Conflicts() []Conflict {
+	return append([]Conflict(nil), m.conflicts...)
+}
+
+// MultiError represents a collection of errors, i.e. from fetching or validating multiple keys.
+type MultiError []error
+
+// Error returns the error message for MultiError, which is the error messages of all the collected errors.
+func (m MultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the collected errors.
+func (m MultiError) Unwrap() []error {
+	return m
+}
+
+// multiError returns the given errors as a MultiError, or nil if there are no errors.
+func multiError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return MultiError(errs)
+}
@@ -0,0 +1,208 @@
+package configurama
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Entry represents a single key and its value, along with the name of its section.
+type Entry struct {
+	Section, Key, Value string
+}
+
+// Entries returns every key in the configuration pool as a list of entries, sorted by section and then by key,
+// which is the same order as used by MustPrettyPrint. This is useful for rendering tables, exporting to CSV or
+// comparing against golden files in tests. Modifying the return value will not affect the configuration pool.
+func (p *Pool) Entries() []Entry {
+	params := p.load()
+	entries := make([]Entry, 0)
+	for sec, keys := range params {
+		for key, val := range keys {
+			entries = append(entries, Entry{sec, key, val})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Section != entries[j].Section {
+			return entries[i].Section < entries[j].Section
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// ToNested returns the configuration pool as a map of sections, each of which is a map[string]interface{}, which is
+// the form accepted by most generic encoders, i.e. for YAML or TOML. Values are strings, unless typed is true, in
+// which case values that are obviously integers, floats or booleans are converted into int64, float64 and bool,
+// respectively. Only "true" and "false" are considered booleans, and numbers with leading zeros are kept as strings,
+// since they are likely identifiers such as zip codes or file modes. Modifying the return value will not affect
+// the configuration pool.
+func (p *Pool) ToNested(typed bool) map[string]interface{} {
+	params := p.load()
+	res := make(map[string]interface{}, len(params))
+	for sec, keys := range params {
+		section := make(map[string]interface{}, len(keys))
+		for key, val := range keys {
+			if typed {
+				section[key] = typedValue(val)
+			} else {
+				section[key] = val
+			}
+		}
+		res[sec] = section
+	}
+	return res
+}
+
+// typedValue returns the given value as an int64, float64 or bool if it's obviously one, see ToNested.
+func typedValue(val string) interface{} {
+	switch val {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	digits := strings.TrimPrefix(val, "-")
+	if digits == "" || digits[0] < '0' || digits[0] > '9' || (len(digits) > 1 && digits[0] == '0' && digits[1] != '.') {
+		return val
+	}
+	if i, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f
+	}
+	return val
+}
+
+// ToEnviron returns the configuration pool as a list of environment variables in the form "NAME=value", suitable
+// for os/exec.Cmd.Env. Names are made up of the given prefix, the section name and the key, joined by sectionSep
+// and keySep respectively, i.e. "APP_DATABASE_DB_HOST" for the prefix "APP", the section "Database" and the
+// key "db.host" when both separators are "_". The prefix and sectionSep are omitted if the prefix is empty, and
+// likewise the section name and keySep are omitted if the section name is empty.
+// Names are sanitized to be valid shell variable names: letters are uppercased, any character other than A-Z, 0-9
+// and underscore is replaced with an underscore, and an underscore is prepended if the name starts with a digit.
+// Since sanitization may cause different keys to end up with the same name, entries are generated in sorted
+// section and key order, and the first entry wins in case of a collision; subsequent entries are discarded.
+// The returned list is sorted by name.
+func (p *Pool) ToEnviron(prefix, sectionSep, keySep string) []string {
+	params := p.load()
+	sections := make([]string, 0, len(params))
+	for sec := range params {
+		sections = append(sections, sec)
+	}
+	sort.Strings(sections)
+
+	seen := make(map[string]bool)
+	environ := make([]string, 0)
+	for _, sec := range sections {
+		keys := make([]string, 0, len(params[sec]))
+		for key := range params[sec] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			var name strings.Builder
+			if prefix != "" {
+				name.WriteString(prefix + sectionSep)
+			}
+			if sec != "" {
+				name.WriteString(sec + keySep)
+			}
+			name.WriteString(key)
+
+			envName := envVarName(name.String())
+			if seen[envName] {
+				continue
+			}
+			seen[envName] = true
+			environ = append(environ, envName+"="+params[sec][key])
+		}
+	}
+	sort.Strings(environ)
+
+	return environ
+}
+
+// envVarName sanitizes the given name so it's usable as an environment variable name in shells.
+func envVarName(name string) string {
+	var out strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			out.WriteRune(r)
+		} else {
+			out.WriteRune('_')
+		}
+	}
+	res := out.String()
+	if res != "" && res[0] >= '0' && res[0] <= '9' {
+		res = "_" + res
+	}
+	return res
+}
+
+// WriteINI writes the configuration pool to w in INI format, with each section as a "[section]" header followed by
+// its keys as "key = value" lines, and sections separated by a blank line. Sections and keys are sorted the same
+// way as by MustPrettyPrint, and empty sections are written as well. Values with leading or trailing whitespace,
+// control characters such as newlines or a leading quote are quoted and escaped as by the QuoteAmbiguous policy,
+// and so are values containing the comment characters ";" or "#". The output can be read back via ParseINI.
+// An error is returned, and nothing is written, if a section name contains "]" or control characters, or if a key
+// is empty, contains "=", ":" or control characters, starts with a comment character or "[", or has leading or
+// trailing whitespace, since such names can't be represented in INI format.
+func (p *Pool) WriteINI(w io.Writer) error {
+	params := p.load()
+	sections := make([]string, 0, len(params))
+	for sec := range params {
+		if strings.Contains(sec, "]") || hasControl(sec) {
+			return fmt.Errorf("unable to write INI: invalid section name %q", sec)
+		}
+		for key := range params[sec] {
+			if key == "" || key != strings.TrimSpace(key) || strings.ContainsAny(key, "=:") ||
+				strings.ContainsAny(key[:1], ";#[") || hasControl(key) {
+				return fmt.Errorf("unable to write INI: section %q: invalid key %q", sec, key)
+			}
+		}
+		sections = append(sections, sec)
+	}
+	sort.Strings(sections)
+
+	bw := bufio.NewWriter(w)
+	for i, sec := range sections {
+		keys := make([]string, 0, len(params[sec]))
+		for key := range params[sec] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		bw.WriteString("[" + sec + "]\n")
+		for _, key := range keys {
+			val := params[sec][key]
+			if strings.ContainsAny(val, ";#") {
+				val = strconv.Quote(val)
+			} else {
+				val = quoteValue(val, QuoteAmbiguous)
+			}
+			bw.WriteString(key + " = " + val + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// hasControl returns true if s contains control characters, such as newlines.
+func hasControl(s string) bool {
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
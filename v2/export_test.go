@@ -0,0 +1,205 @@
+package configurama
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db": {
+			"port": "3306",
+			"host": "localhost",
+		},
+		"cache": {
+			"ttl": "5m",
+		},
+		"empty": {},
+	})
+
+	expected := []Entry{
+		{"cache", "ttl", "5m"},
+		{"db", "host", "localhost"},
+		{"db", "port", "3306"},
+	}
+	if entries := c.Entries(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected entries %v, got %v", expected, entries)
+	}
+
+	if entries := New(map[string]map[string]string{}).Entries(); len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+}
+
+func TestToNested(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db": {
+			"host":   "localhost",
+			"port":   "3306",
+			"ratio":  "-0.5",
+			"debug":  "true",
+			"mode":   "0644",
+			"zero":   "0",
+			"yes":    "yes",
+			"exp":    "1e3",
+			"hex":    "0x1f",
+			"nan":    "NaN",
+			"period": "1.",
+		},
+	})
+
+	expected := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host":   "localhost",
+			"port":   int64(3306),
+			"ratio":  -0.5,
+			"debug":  true,
+			"mode":   "0644",
+			"zero":   int64(0),
+			"yes":    "yes",
+			"exp":    1000.0,
+			"hex":    "0x1f",
+			"nan":    "NaN",
+			"period": 1.0,
+		},
+	}
+	if nested := c.ToNested(true); !reflect.DeepEqual(nested, expected) {
+		t.Errorf("expected %v, got %v", expected, nested)
+	}
+
+	nested := c.ToNested(false)
+	if port := nested["db"].(map[string]interface{})["port"]; port != "3306" {
+		t.Errorf("expected port to be a string, got %#v", port)
+	}
+}
+
+func TestToEnviron(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"Database": {
+			"db.host": "discarded",
+			"db-host": "first",
+			"db.port": "3306",
+		},
+		"": {
+			"9lives": "cat",
+		},
+		"cache": {
+			"ttl": "5m",
+		},
+	})
+
+	tt := map[string]struct {
+		prefix, sectionSep, keySep string
+		expected                   []string
+	}{
+		"with prefix": {
+			"APP", "_", "_",
+			[]string{"APP_9LIVES=cat", "APP_CACHE_TTL=5m", "APP_DATABASE_DB_HOST=first", "APP_DATABASE_DB_PORT=3306"},
+		},
+		"without prefix": {
+			"", "_", "__",
+			[]string{"CACHE__TTL=5m", "DATABASE__DB_HOST=first", "DATABASE__DB_PORT=3306", "_9LIVES=cat"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual := c.ToEnviron(tc.prefix, tc.sectionSep, tc.keySep)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// errWriter is an io.Writer that always fails with the given error.
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestWriteINI(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"db": {
+			"port":  "3306",
+			"host":  "localhost",
+			"motd":  "hello\nworld",
+			"space": " padded ",
+		},
+		"cache": {},
+		"api": {
+			"url": "https://example.com/?a=b",
+		},
+	})
+
+	var b strings.Builder
+	verifyNil(t, c.WriteINI(&b))
+	expected := `[api]
+url = https://example.com/?a=b
+
+[cache]
+
+[db]
+host = localhost
+motd = "hello\nworld"
+port = 3306
+space = " padded "
+`
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+
+	errWrite := errors.New("disk full")
+	if err := c.WriteINI(errWriter{errWrite}); !errors.Is(err, errWrite) {
+		t.Errorf("expected error %v, got %v", errWrite, err)
+	}
+
+	t.Run("it round-trips through ParseINI", func(t *testing.T) {
+		params := map[string]map[string]string{
+			"db [primary": {
+				"comment":   "a ; b # c",
+				"hash":      "#1",
+				"query":     "a=b=c",
+				"url":       "http://localhost:8080",
+				"quoted":    `"quoted" value`,
+				"multiline": "first\nsecond",
+				"padded":    " padded ",
+				"empty":     "",
+				"inner key": "value",
+			},
+			"empty": {},
+		}
+		var b strings.Builder
+		verifyNil(t, New(params).WriteINI(&b))
+		parsed, err := ParseINI(strings.NewReader(b.String()))
+		verifyNil(t, err)
+		verifyEqual(t, parsed, params)
+	})
+
+	t.Run("it rejects names that can't be represented", func(t *testing.T) {
+		tt := map[string]map[string]map[string]string{
+			"section with bracket": {"db]": {"host": "a"}},
+			"section with newline": {"db\n": {"host": "a"}},
+			"key with equals":      {"db": {"a=b": "c"}},
+			"key with colon":       {"db": {"a:b": "c"}},
+			"key with newline":     {"db": {"a\nb": "c"}},
+			"key with comment":     {"db": {";host": "a"}},
+			"key with bracket":     {"db": {"[host": "a"}},
+			"key with whitespace":  {"db": {" host": "a"}},
+			"empty key":            {"db": {"": "a"}},
+		}
+
+		for name, params := range tt {
+			t.Run(name, func(t *testing.T) {
+				var b strings.Builder
+				if err := New(params).WriteINI(&b); err == nil {
+					t.Error("expected error, got nil")
+				}
+				if b.Len() != 0 {
+					t.Errorf("expected nothing to be written, got %q", b.String())
+				}
+			})
+		}
+	})
+}
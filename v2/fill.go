@@ -0,0 +1,135 @@
+package configurama
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Fill sets keys in the section of the given name from the exported fields of the given struct, or pointer to a
+// struct, creating the section if it doesn't exist. This is the counterpart of extracting values via the getters,
+// making it possible to bring runtime configuration back into a pool, i.e. for pretty-printing or for comparing it
+// to the configuration on disk.
+// Keys are named after the fields, unless a different name is given via a tag, i.e. `configurama:"db.host"`.
+// Fields tagged with `configurama:"-"` are skipped.
+// Strings, booleans, integers and floats are converted via strconv, time.Duration via its String method and
+// time.Time using the RFC 3339 layout. Other types are supported if they implement fmt.Stringer. Pointers to
+// supported types are dereferenced, and nil pointers result in empty values. Fields of embedded structs of exported
+// types without a tag are filled as if they were fields of the outer struct, unless the outer struct has a field with
+// the same key, while embedded structs of unexported types are skipped like other unexported fields.
+// An error is returned for fields of any other type, in which case the pool is left unmodified.
+func (p *Pool) Fill(section string, in interface{}) error {
+	v := reflect.ValueOf(in)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("unable to fill section %q: expected a struct, got %T", section, in)
+	}
+
+	params := make(map[string]string)
+	if err := fillFields(params, v); err != nil {
+		return fmt.Errorf("unable to fill section %q: %w", section, err)
+	}
+	return p.Merge(map[string]map[string]string{section: params}, Overwrite)
+}
+
+// fillFields adds the exported fields of the given struct to params, see Fill. Keys that are already in params
+// aren't overwritten by fields of embedded structs.
+func fillFields(params map[string]string, v reflect.Value) error {
+	embedded := make([]reflect.Value, 0)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, tagged := field.Tag.Lookup("configurama")
+		if tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			continue // Unexported field, including embedded structs of unexported types.
+		}
+		if field.Anonymous && !tagged && isEmbeddedStruct(field.Type) {
+			if f := v.Field(i); f.Kind() != reflect.Ptr || !f.IsNil() {
+				embedded = append(embedded, reflect.Indirect(f))
+			}
+			continue
+		}
+		key := field.Name
+		if tag != "" {
+			key = tag
+		}
+
+		val, ok := formatValue(v.Field(i))
+		if !ok {
+			return fmt.Errorf("unsupported type %s for field %s", field.Type, field.Name)
+		}
+		params[key] = val
+	}
+
+	for _, e := range embedded {
+		fields := make(map[string]string)
+		if err := fillFields(fields, e); err != nil {
+			return err
+		}
+		for key, val := range fields {
+			if _, ok := params[key]; !ok {
+				params[key] = val
+			}
+		}
+	}
+	return nil
+}
+
+// isEmbeddedStruct returns true if the given type of an embedded field is a struct, or pointer to a struct, whose
+// fields are filled individually, rather than a type that's supported as a whole, i.e. time.Time.
+func isEmbeddedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	_, ok := formatValue(reflect.Zero(t))
+	return t.Kind() == reflect.Struct && !ok && !reflect.PtrTo(t).Implements(stringerType)
+}
+
+// stringerType is the type of fmt.Stringer, used to check whether nil pointers are supported by formatValue.
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// formatValue returns the given value as a string, see Fill for the supported types.
+// The return value ok is false if the type of the value is not supported.
+func formatValue(v reflect.Value) (s string, ok bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			// Calling String on a nil pointer may panic.
+			_, ok = formatValue(reflect.Zero(v.Type().Elem()))
+			return "", ok || v.Type().Implements(stringerType)
+		}
+		if s, ok = formatValue(v.Elem()); ok {
+			return s, true
+		}
+	}
+
+	switch val := v.Interface().(type) {
+	case time.Duration:
+		return val.String(), true
+	case time.Time:
+		return val.Format(time.RFC3339), true
+	case fmt.Stringer:
+		return val.String(), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true
+	}
+	return "", false
+}
@@ -0,0 +1,117 @@
+package configurama
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Limits is embedded in structs passed to Fill.
+type Limits struct {
+	Min, Max int
+	Tags     []string `configurama:"-"`
+}
+
+func TestFill(t *testing.T) {
+	type Server struct {
+		Host     string `configurama:"db.host"`
+		Port     uint16
+		Ratio    float64
+		Debug    bool
+		Timeout  time.Duration
+		Started  time.Time
+		IP       net.IP
+		Password string `configurama:"-"`
+		internal string
+	}
+
+	in := Server{
+		Host:     "localhost",
+		Port:     3306,
+		Ratio:    0.5,
+		Debug:    true,
+		Timeout:  90 * time.Second,
+		Started:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		IP:       net.IPv4(127, 0, 0, 1),
+		Password: "secret",
+		internal: "hidden",
+	}
+
+	t.Run("it fills a new section", func(t *testing.T) {
+		c := New(map[string]map[string]string{})
+		verifyNil(t, c.Fill("server", &in))
+		verifyEqual(t, c.Raw(), map[string]map[string]string{"server": {
+			"db.host": "localhost",
+			"Port":    "3306",
+			"Ratio":   "0.5",
+			"Debug":   "true",
+			"Timeout": "1m30s",
+			"Started": "2020-01-02T03:04:05Z",
+			"IP":      "127.0.0.1",
+		}})
+	})
+
+	t.Run("it keeps other keys in an existing section", func(t *testing.T) {
+		c := New(map[string]map[string]string{"server": {"db.host": "remote", "name": "main"}})
+		verifyNil(t, c.Fill("server", struct{ Port int }{8080}))
+		verifyEqual(t, c.Raw(), map[string]map[string]string{"server": {"db.host": "remote", "name": "main", "Port": "8080"}})
+	})
+
+	t.Run("it fills nil pointers with empty values", func(t *testing.T) {
+		c := New(map[string]map[string]string{})
+		verifyNil(t, c.Fill("api", struct {
+			URL      *url.URL
+			Fallback *url.URL
+		}{nil, &url.URL{Scheme: "https", Host: "example.com"}}))
+		verifyEqual(t, c.Raw(), map[string]map[string]string{"api": {"URL": "", "Fallback": "https://example.com"}})
+	})
+
+	t.Run("it dereferences pointers", func(t *testing.T) {
+		started, count := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), 3
+		c := New(map[string]map[string]string{})
+		verifyNil(t, c.Fill("job", struct {
+			Started *time.Time
+			Count   *int
+			Retries *int
+		}{&started, &count, nil}))
+		verifyEqual(t, c.Raw(), map[string]map[string]string{"job": {
+			"Started": "2020-01-02T03:04:05Z",
+			"Count":   "3",
+			"Retries": "",
+		}})
+	})
+
+	t.Run("it fills fields of embedded structs", func(t *testing.T) {
+		type Service struct {
+			Limits
+			*Server
+			Name string
+			Max  int
+		}
+		c := New(map[string]map[string]string{})
+		verifyNil(t, c.Fill("svc", Service{Limits: Limits{Max: 1, Min: 2}, Name: "api", Max: 10}))
+		verifyEqual(t, c.Raw(), map[string]map[string]string{"svc": {"Name": "api", "Max": "10", "Min": "2"}})
+	})
+
+	t.Run("it rejects unsupported types", func(t *testing.T) {
+		c := New(map[string]map[string]string{"server": {"name": "main"}})
+		if err := c.Fill("server", struct {
+			Name string
+			Tags []string
+		}{"other", nil}); err == nil {
+			t.Error("expected error for unsupported type, got nil")
+		}
+		if err := c.Fill("server", struct{ Tags *[]string }{nil}); err == nil {
+			t.Error("expected error for nil pointer of unsupported type, got nil")
+		}
+		type Tagged struct{ Tags []string }
+		if err := c.Fill("server", struct{ Tagged }{Tagged{[]string{"a"}}}); err == nil {
+			t.Error("expected error for unsupported type in embedded struct, got nil")
+		}
+		if err := c.Fill("server", "not a struct"); err == nil {
+			t.Error("expected error for non-struct, got nil")
+		}
+		verifyEqual(t, c.Raw(), map[string]map[string]string{"server": {"name": "main"}})
+	})
+}
@@ -0,0 +1,277 @@
+package configurama
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateJSONSchema validates a parameter as a JSON document against the given JSON Schema.
+// Only a subset of JSON Schema is supported, which keeps the package free of dependencies:
+// type, enum, const, properties, required, additionalProperties (boolean only), items (single schema only),
+// minimum, maximum, minLength, maxLength, pattern, minItems and maxItems. The annotations $schema, $id, $comment,
+// title, description, default and examples are allowed but have no effect.
+// A SchemaValidationError is returned if the value isn't valid JSON, doesn't match the schema, or if the
+// schema itself can't be parsed or uses any other keyword, i.e. oneOf or $ref, so that no part of a schema is
+// silently skipped.
+var ValidateJSONSchema = func(schema string) Option {
+	var parsed map[string]interface{}
+	schemaErr := json.Unmarshal([]byte(schema), &parsed)
+	if schemaErr == nil {
+		schemaErr = checkSchema(parsed, "$")
+	}
+
+	return func(o *option) {
+		o.validate("jsonschema", func(key, value string) error {
+			if schemaErr != nil {
+				return SchemaValidationError{key, "invalid schema: " + schemaErr.Error()}
+			}
+			var doc interface{}
+			if err := json.Unmarshal([]byte(value), &doc); err != nil {
+				return SchemaValidationError{key, "invalid JSON: " + err.Error()}
+			}
+			if reason := matchSchema(parsed, doc, "$"); reason != "" {
+				return SchemaValidationError{key, reason}
+			}
+			return nil
+		})
+	}
+}
+
+// ValidateJSONFields validates a parameter as a JSON object containing all of the given top-level fields, which
+// is a lightweight alternative to ValidateJSONSchema for checking that i.e. "id" and "name" are present. Fields
+// with a null value are considered present.
+// A ConversionError is returned if the value isn't a JSON object, and a JSONFieldsValidationError listing all
+// missing fields, in the order given, is returned if any fields are missing.
+var ValidateJSONFields = func(required ...string) Option {
+	return func(o *option) {
+		o.validate("jsonfields", func(key, value string) error {
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(value), &doc); err != nil || doc == nil {
+				return ConversionError{key, value, "JSON object"}
+			}
+			missing := make([]string, 0)
+			for _, field := range required {
+				if _, ok := doc[field]; !ok {
+					missing = append(missing, strconv.Quote(field))
+				}
+			}
+			if len(missing) > 0 {
+				return JSONFieldsValidationError{key, strings.Join(missing, ", ")}
+			}
+			return nil
+		})
+	}
+}
+
+// schemaKeywords contains the JSON Schema keywords supported by ValidateJSONSchema, including annotations.
+var schemaKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "properties": true, "required": true, "additionalProperties": true,
+	"items": true, "minimum": true, "maximum": true, "minLength": true, "maxLength": true, "pattern": true,
+	"minItems": true, "maxItems": true,
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "default": true,
+	"examples": true,
+}
+
+// checkSchema returns an error if the given schema, or any of its subschemas, uses keywords or keyword forms
+// that aren't supported by matchSchema.
+func checkSchema(schema map[string]interface{}, path string) error {
+	if schema == nil {
+		return fmt.Errorf("%s: expected a schema object", path)
+	}
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		if !schemaKeywords[keyword] {
+			return fmt.Errorf("%s: unsupported keyword %q", path, keyword)
+		}
+	}
+	if additional, ok := schema["additionalProperties"]; ok {
+		if _, ok = additional.(bool); !ok {
+			return fmt.Errorf("%s: unsupported non-boolean additionalProperties", path)
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		sub, ok := items.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unsupported non-object items", path)
+		}
+		if err := checkSchema(sub, path+".items"); err != nil {
+			return err
+		}
+	}
+	if properties, ok := schema["properties"]; ok {
+		props, ok := properties.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected properties to be an object", path)
+		}
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, _ := props[name].(map[string]interface{})
+			if err := checkSchema(sub, path+".properties."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchSchema matches the given JSON document against the given schema. It returns a description of the
+// first mismatch found, prefixed with the path to the offending element, or an empty string if the document matches.
+func matchSchema(schema map[string]interface{}, doc interface{}, path string) string {
+	if typ, ok := schema["type"]; ok {
+		var types []interface{}
+		switch t := typ.(type) {
+		case string:
+			types = []interface{}{t}
+		case []interface{}:
+			types = t
+		}
+		var matched bool
+		for _, t := range types {
+			if name, ok := t.(string); ok && matchType(name, doc) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("%s: expected type %v", path, typ)
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		var matched bool
+		for _, e := range enum {
+			if reflect.DeepEqual(e, doc) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("%s: value not in enum %v", path, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, doc) {
+		return fmt.Sprintf("%s: expected constant %v", path, c)
+	}
+
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		return matchObject(schema, d, path)
+	case []interface{}:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(d)) < min {
+			return fmt.Sprintf("%s: expected at least %v items", path, min)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(d)) > max {
+			return fmt.Sprintf("%s: expected at most %v items", path, max)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range d {
+				if reason := matchSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); reason != "" {
+					return reason
+				}
+			}
+		}
+	case string:
+		length := float64(len([]rune(d)))
+		if min, ok := schema["minLength"].(float64); ok && length < min {
+			return fmt.Sprintf("%s: expected at least %v characters", path, min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && length > max {
+			return fmt.Sprintf("%s: expected at most %v characters", path, max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Sprintf("%s: invalid pattern %q", path, pattern)
+			}
+			if !regex.MatchString(d) {
+				return fmt.Sprintf("%s: expected to match pattern %q", path, pattern)
+			}
+		}
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && d < min {
+			return fmt.Sprintf("%s: expected minimum %v", path, min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && d > max {
+			return fmt.Sprintf("%s: expected maximum %v", path, max)
+		}
+	}
+
+	return ""
+}
+
+// matchObject matches the given JSON object against the object-related keywords of the given schema.
+func matchObject(schema, doc map[string]interface{}, path string) string {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, ok = doc[name]; !ok {
+					return fmt.Sprintf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propSchema, ok := properties[name].(map[string]interface{})
+		if !ok {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				return fmt.Sprintf("%s: unexpected property %q", path, name)
+			}
+			continue
+		}
+		if reason := matchSchema(propSchema, doc[name], path+"."+name); reason != "" {
+			return reason
+		}
+	}
+
+	return ""
+}
+
+// matchType returns true if the given JSON document is of the given JSON Schema type.
+func matchType(name string, doc interface{}) bool {
+	switch name {
+	case "object":
+		_, ok := doc.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := doc.([]interface{})
+		return ok
+	case "string":
+		_, ok := doc.(string)
+		return ok
+	case "number":
+		_, ok := doc.(float64)
+		return ok
+	case "integer":
+		f, ok := doc.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := doc.(bool)
+		return ok
+	case "null":
+		return doc == nil
+	}
+	return false
+}
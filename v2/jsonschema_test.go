@@ -0,0 +1,118 @@
+package configurama
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["name", "port"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"mode": {"enum": ["fast", "slow"]},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+		}
+	}`
+
+	tt := map[string]struct {
+		value, reason string
+	}{
+		"valid document":      {`{"name": "db", "port": 3306, "mode": "fast", "tags": ["a"]}`, ""},
+		"invalid JSON":        {`{"name": `, "invalid JSON"},
+		"wrong type":          {`["db"]`, "$: expected type object"},
+		"missing property":    {`{"name": "db"}`, `$: missing required property "port"`},
+		"unexpected property": {`{"name": "db", "port": 1, "host": "x"}`, `$: unexpected property "host"`},
+		"non-integer":         {`{"name": "db", "port": 1.5}`, "$.port: expected type integer"},
+		"below minimum":       {`{"name": "db", "port": 0}`, "$.port: expected minimum 1"},
+		"pattern mismatch":    {`{"name": "DB", "port": 1}`, `$.name: expected to match pattern "^[a-z]+$"`},
+		"enum mismatch":       {`{"name": "db", "port": 1, "mode": "medium"}`, "$.mode: value not in enum"},
+		"too many items":      {`{"name": "db", "port": 1, "tags": ["a", "b", "c"]}`, "$.tags: expected at most 2 items"},
+		"wrong item type":     {`{"name": "db", "port": 1, "tags": [1]}`, "$.tags[0]: expected type string"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := checkApplyOptions("x", tc.value, true, ValidateJSONSchema(schema))
+			if tc.reason == "" {
+				verifyNil(t, err)
+				return
+			}
+			var schemaErr SchemaValidationError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected SchemaValidationError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.reason) {
+				t.Errorf("expected error to contain %q, got %q", tc.reason, err.Error())
+			}
+		})
+	}
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := checkApplyOptions("x", "{}", true, ValidateJSONSchema(`{`))
+		if err == nil || !strings.Contains(err.Error(), "invalid schema") {
+			t.Errorf("expected invalid schema error, got %v", err)
+		}
+	})
+
+	unsupported := map[string]struct {
+		schema, reason string
+	}{
+		"oneOf":             {`{"oneOf": [{"type": "string"}, {"type": "integer"}]}`, `$: unsupported keyword "oneOf"`},
+		"ref":               {`{"properties": {"a": {"$ref": "#/definitions/a"}}}`, `$.properties.a: unsupported keyword "$ref"`},
+		"nested format":     {`{"items": {"type": "string", "format": "email"}}`, `$.items: unsupported keyword "format"`},
+		"tuple items":       {`{"items": [{"type": "string"}]}`, "$: unsupported non-object items"},
+		"schema additional": {`{"additionalProperties": {"type": "string"}}`, "$: unsupported non-boolean additionalProperties"},
+	}
+
+	for name, tc := range unsupported {
+		t.Run("unsupported "+name, func(t *testing.T) {
+			_, err := checkApplyOptions("x", `{}`, true, ValidateJSONSchema(tc.schema))
+			var schemaErr SchemaValidationError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected SchemaValidationError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "invalid schema: "+tc.reason) {
+				t.Errorf("expected error to contain %q, got %q", tc.reason, err.Error())
+			}
+		})
+	}
+
+	t.Run("annotations", func(t *testing.T) {
+		_, err := checkApplyOptions("x", `"db"`, true, ValidateJSONSchema(`{"$schema": "x", "title": "Name", "type": "string"}`))
+		verifyNil(t, err)
+	})
+}
+
+func TestValidateJSONFields(t *testing.T) {
+	tt := map[string]struct {
+		value    string
+		expected error
+	}{
+		"all fields":     {`{"id": 1, "name": "db", "extra": true}`, nil},
+		"null field":     {`{"id": null, "name": "db"}`, nil},
+		"missing field":  {`{"id": 1}`, JSONFieldsValidationError{"x", `"name"`}},
+		"missing fields": {`{"extra": true}`, JSONFieldsValidationError{"x", `"id", "name"`}},
+		"array":          {`[{"id": 1, "name": "db"}]`, ConversionError{"x", `[{"id": 1, "name": "db"}]`, "JSON object"}},
+		"null":           {`null`, ConversionError{"x", "null", "JSON object"}},
+		"invalid JSON":   {`{"id": `, ConversionError{"x", `{"id": `, "JSON object"}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := checkApplyOptions("x", tc.value, true, ValidateJSONFields("id", "name"))
+			if err != tc.expected {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	expected := `JSON fields validation failed for key "x": missing fields "id", "name"`
+	if _, err := checkApplyOptions("x", "{}", true, ValidateJSONFields("id", "name")); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
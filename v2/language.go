@@ -0,0 +1,143 @@
+package configurama
+
+import "strings"
+
+// ValidateLanguageTag validates a parameter as a well-formed BCP 47 language tag, i.e. "en-US", "pt-BR" or
+// "zh-Hant-TW". Subtags may be separated by hyphens or underscores, and matching is case-insensitive.
+// Only the syntax of the tag is checked, not whether its subtags are registered, which keeps the package free of
+// the dependency on golang.org/x/text. Irregular grandfathered tags such as "i-klingon" are not supported.
+// A LanguageTagValidationError is returned if the parameter is not a well-formed language tag.
+var ValidateLanguageTag = func() Option {
+	return func(o *option) {
+		o.validate("languageTag", func(key, value string) error {
+			if _, ok := normalizeLanguageTag(value); !ok {
+				return LanguageTagValidationError(key)
+			}
+			return nil
+		})
+	}
+}
+
+// LanguageTag returns the value for the given key as a BCP 47 language tag in its canonical form, i.e. "en-US" for
+// "EN_us". The language is lowercased, the script titlecased, the region uppercased and all other subtags
+// lowercased, and subtags are separated by hyphens. The same forms as for ValidateLanguageTag are accepted.
+// A NoKeyError is returned if the key is required but does not exist.
+// A ConversionError is returned if the value is not a well-formed language tag.
+// A RegExpValidationError, EnumValidationError or custom error may be returned depending
+// on which validation options were passed.
+func (s Params) LanguageTag(key string, options ...Option) (string, error) {
+	val, err := s.String(key, options...)
+	if err != nil || val == "" {
+		return "", err
+	}
+	tag, ok := normalizeLanguageTag(val)
+	if !ok {
+		return "", ConversionError{key, val, "language tag"}
+	}
+	return tag, nil
+}
+
+// normalizeLanguageTag returns the given BCP 47 language tag in its canonical form. The return value ok is false
+// if the value is not a well-formed language tag, following the "langtag" and "privateuse" productions of RFC 5646.
+func normalizeLanguageTag(value string) (tag string, ok bool) {
+	subtags := strings.Split(strings.ToLower(strings.ReplaceAll(value, "_", "-")), "-")
+	for _, subtag := range subtags {
+		if len(subtag) == 0 || len(subtag) > 8 || !isAlphaNum(subtag) {
+			return "", false
+		}
+	}
+
+	i := 0
+	next := func() string {
+		if i < len(subtags) {
+			return subtags[i]
+		}
+		return ""
+	}
+
+	if next() != "x" {
+		// Language, with up to three extended language subtags for two or three letter languages.
+		lang := next()
+		if len(lang) < 2 || !isAlpha(lang) {
+			return "", false
+		}
+		i++
+		if len(lang) <= 3 {
+			for n := 0; n < 3 && len(next()) == 3 && isAlpha(next()); n++ {
+				i++
+			}
+		}
+
+		// Script.
+		if s := next(); len(s) == 4 && isAlpha(s) {
+			subtags[i] = strings.ToUpper(s[:1]) + s[1:]
+			i++
+		}
+
+		// Region.
+		if s := next(); (len(s) == 2 && isAlpha(s)) || (len(s) == 3 && isDigits(s)) {
+			subtags[i] = strings.ToUpper(s)
+			i++
+		}
+
+		// Variants.
+		for s := next(); len(s) >= 5 || (len(s) == 4 && s[0] >= '0' && s[0] <= '9'); s = next() {
+			i++
+		}
+
+		// Extensions, each consisting of a singleton followed by at least one subtag of two to eight characters.
+		for s := next(); len(s) == 1 && s != "x"; s = next() {
+			i++
+			n := 0
+			for ; len(next()) >= 2; n++ {
+				i++
+			}
+			if n == 0 {
+				return "", false
+			}
+		}
+	}
+
+	// Private use, consisting of "x" followed by at least one subtag of one to eight characters.
+	if next() == "x" {
+		if i == len(subtags)-1 {
+			return "", false
+		}
+		i = len(subtags)
+	}
+
+	if i != len(subtags) {
+		return "", false
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+// isAlpha returns true if s consists of ASCII letters only.
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits returns true if s consists of ASCII digits only.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlphaNum returns true if s consists of ASCII letters and digits only.
+func isAlphaNum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package configurama
+
+import "testing"
+
+func TestLanguageTag(t *testing.T) {
+	tt := map[string]struct {
+		value, expected string
+		ok              bool
+	}{
+		"language":               {"en", "en", true},
+		"language and region":    {"en-US", "en-US", true},
+		"underscore, mixed case": {"PT_br", "pt-BR", true},
+		"script":                 {"zh-hant-tw", "zh-Hant-TW", true},
+		"numeric region":         {"es-419", "es-419", true},
+		"extended language":      {"zh-yue-HK", "zh-yue-HK", true},
+		"variant":                {"de-CH-1996", "de-CH-1996", true},
+		"extension":              {"en-US-u-ca-gregory", "en-US-u-ca-gregory", true},
+		"private use":            {"en-x-Custom", "en-x-custom", true},
+		"private use only":       {"x-whatever", "x-whatever", true},
+		"too short":              {"e", "", false},
+		"digits in language":     {"e1-US", "", false},
+		"empty subtag":           {"en--US", "", false},
+		"trailing separator":     {"en-", "", false},
+		"subtag too long":        {"en-abcdefghi", "", false},
+		"invalid character":      {"en-U$", "", false},
+		"duplicate region":       {"en-US-GB", "", false},
+		"empty extension":        {"en-u", "", false},
+		"empty private use":      {"en-x", "", false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sec := Params{"locale": tc.value}
+			actual, err := sec.LanguageTag("locale")
+			if tc.ok && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if !tc.ok && err != (ConversionError{"locale", tc.value, "language tag"}) {
+				t.Errorf("expected ConversionError, got %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %q, got %q", tc.expected, actual)
+			}
+
+			_, err = sec.String("locale", ValidateLanguageTag())
+			if tc.ok && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if !tc.ok && err != LanguageTagValidationError("locale") {
+				t.Errorf("expected error %v, got %v", LanguageTagValidationError("locale"), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package configurama
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NewFromFS returns a new configuration pool containing the data parsed from the file at the given path
+// within the given filesystem. This works with any fs.FS, i.e. embed.FS for bundled defaults, os.DirFS for
+// files on disk and fstest.MapFS for test fixtures.
+// The error from opening the file is returned as-is, errors from parsing are wrapped with the file path.
+func NewFromFS(fsys fs.FS, path string, parse func(io.Reader) (map[string]map[string]string, error)) (*Pool, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	params, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %q: %w", path, err)
+	}
+	return New(params), nil
+}
+
+// MergeGlob returns a new configuration pool containing the data parsed from all files matching the given pattern,
+// as accepted by filepath.Glob. Files are merged in lexical order of their paths, using the given strategy, which
+// implements the "conf.d" convention of drop-in configuration files, i.e. "/etc/myapp/conf.d/*.conf".
+// An empty pool is returned if no files match. Errors from reading, parsing and merging, including conflicts
+// with the Report strategy, are wrapped with the path of the offending file.
+func MergeGlob(pattern string, parse func(io.Reader) (map[string]map[string]string, error), strategy Strategy) (*Pool, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	p := New(map[string]map[string]string{})
+	for _, path := range paths {
+		params, err := parseFile(path, parse)
+		if err != nil {
+			return nil, err
+		}
+		if err = p.Merge(params, strategy); err != nil {
+			return nil, fmt.Errorf("unable to merge %q: %w", path, err)
+		}
+	}
+	return p, nil
+}
+
+// parseFile parses the file at the given path using the given parse function.
+func parseFile(path string, parse func(io.Reader) (map[string]map[string]string, error)) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	params, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %q: %w", path, err)
+	}
+	return params, nil
+}
+
+// ResolveFileRefs replaces file references with the contents of the files they refer to. For every key ending in
+// the given suffix, the file at the path given by its value is read, and the key without the suffix is set to the
+// file's contents with leading and trailing whitespace removed. The key with the suffix is then removed. With the
+// suffix "_file", the key "password_file" pointing at a file containing "secret" is thus replaced by the key
+// "password" with the value "secret". This is the convention used by i.e. Docker secrets.
+// All files are read before the pool is modified. If any file can't be read, a MultiError is returned containing
+// an error for each file, and the pool is left unmodified. References that are changed while the files are read,
+// i.e. via Set or Merge, are left as they are.
+func (p *Pool) ResolveFileRefs(suffix string) error {
+	if suffix == "" {
+		return nil
+	}
+
+	type fileRef struct {
+		section, key, path, contents string
+	}
+
+	refs := make([]fileRef, 0)
+	for sec, params := range p.load() {
+		for key, val := range params {
+			if strings.HasSuffix(key, suffix) && key != suffix {
+				refs = append(refs, fileRef{section: sec, key: key, path: val})
+			}
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].section != refs[j].section {
+			return refs[i].section < refs[j].section
+		}
+		return refs[i].key < refs[j].key
+	})
+
+	errs := make([]error, 0)
+	for i, ref := range refs {
+		contents, err := os.ReadFile(ref.path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("section %q, key %q: %w", ref.section, ref.key, err))
+			continue
+		}
+		refs[i].contents = strings.TrimSpace(string(contents))
+	}
+	if len(errs) > 0 {
+		return MultiError(errs)
+	}
+
+	values := make([]resolvedValue, 0, len(refs))
+	for _, ref := range refs {
+		values = append(values, resolvedValue{
+			section: ref.section,
+			key:     ref.key,
+			from:    ref.path,
+			to:      ref.contents,
+			toKey:   strings.TrimSuffix(ref.key, suffix),
+		})
+	}
+	p.applyResolved(values)
+	return nil
+}
+
+// LoadJSON returns a new configuration pool containing the data parsed from the given JSON, see ParseJSON.
+func LoadJSON(r io.Reader) (*Pool, error) {
+	params, err := ParseJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	return New(params), nil
+}
+
+// ParseJSON parses JSON consisting of an object of sections, each of which is an object of keys and values, i.e.
+// {"db": {"host": "localhost", "port": 3306}}. Numbers and booleans are converted into strings, null is converted
+// into an empty string, and arrays of such values are joined with commas so they can be split via Params.Strings.
+// Objects nested within sections are flattened using dotted keys, i.e. {"db": {"master": {"host": "a"}}} results
+// in the key "master.host" in the section "db".
+// ParseJSON can be passed to NewFromFS and MergeGlob. An error is returned if the JSON isn't an object of objects,
+// including a top-level null, if it's followed by more data, if an array contains objects or arrays, or if
+// flattening results in duplicate keys.
+func ParseJSON(r io.Reader) (map[string]map[string]string, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var data map[string]interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON: %w", err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("unable to decode JSON: expected an object of sections, got null")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unable to decode JSON: unexpected data after the top-level object")
+	}
+	return parseSections(data)
+}
+
+// LoadINI returns a new configuration pool containing the data parsed from the given INI, see ParseINI. Unlike
+// pools created from the result of ParseINI, OrderedSections returns the sections in the order they first occur.
+func LoadINI(r io.Reader) (*Pool, error) {
+	params, order, err := parseINI(r)
+	if err != nil {
+		return nil, err
+	}
+	p := New(params)
+	p.order = order
+	return p, nil
+}
+
+// ParseINI parses INI consisting of "[section]" headers, each followed by "key = value" or "key: value" lines, as
+// written by Pool.WriteINI and MustPrettyPrint respectively. The key ends at the first "=" or ":". Whitespace around
+// keys and values is trimmed, and values starting with a double quote are unquoted as Go string literals, i.e.
+// "\"hello\\nworld\"" becomes "hello" followed by a newline and "world". Blank lines and lines starting with ";" or
+// "#" are ignored, and there's no limit on the length of lines.
+// ParseINI can be passed to NewFromFS and MergeGlob. An error identifying the line is returned for keys outside
+// of a section, lines that are neither headers nor key/value pairs, invalid quoted values and duplicate keys.
+func ParseINI(r io.Reader) (map[string]map[string]string, error) {
+	params, _, err := parseINI(r)
+	return params, err
+}
+
+// parseINI parses INI as described by ParseINI, and also returns the section names in the order they first occur.
+func parseINI(r io.Reader) (map[string]map[string]string, []string, error) {
+	params := make(map[string]map[string]string)
+	order := make([]string, 0)
+	var sec map[string]string
+	var name string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == ';' || line[0] == '#':
+			continue
+		case line[0] == '[' && line[len(line)-1] == ']':
+			name = line[1 : len(line)-1]
+			if sec = params[name]; sec == nil {
+				sec = make(map[string]string)
+				params[name] = sec
+				order = append(order, name)
+			}
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return nil, nil, fmt.Errorf("unable to parse INI: line %d: expected a section header or a key/value pair", n)
+		}
+		if sec == nil {
+			return nil, nil, fmt.Errorf("unable to parse INI: line %d: key outside of a section", n)
+		}
+		key, val := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if strings.HasPrefix(val, `"`) {
+			unquoted, err := strconv.Unquote(val)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to parse INI: line %d: invalid quoted value: %w", n, err)
+			}
+			val = unquoted
+		}
+		if _, ok := sec[key]; ok {
+			return nil, nil, fmt.Errorf("unable to parse INI: line %d: duplicate key %q in section %q", n, key, name)
+		}
+		sec[key] = val
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse INI: %w", err)
+	}
+	return params, order, nil
+}
+
+// parseSections converts the given generic data, as decoded from i.e. JSON, into sections, see ParseJSON.
+func parseSections(data map[string]interface{}) (map[string]map[string]string, error) {
+	params := make(map[string]map[string]string, len(data))
+	for sec, val := range data {
+		keys, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("section %q: expected an object of keys, got %s", sec, describeValue(val))
+		}
+		params[sec] = make(map[string]string, len(keys))
+		if err := flattenKeys(params[sec], "", keys); err != nil {
+			return nil, fmt.Errorf("section %q: %w", sec, err)
+		}
+	}
+	return params, nil
+}
+
+// flattenKeys adds the given keys, prefixed with prefix, to params. Nested objects are flattened using dotted keys.
+func flattenKeys(params map[string]string, prefix string, keys map[string]interface{}) error {
+	for key, val := range keys {
+		key = prefix + key
+		if nested, ok := val.(map[string]interface{}); ok {
+			if err := flattenKeys(params, key+".", nested); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var s string
+		if list, ok := val.([]interface{}); ok {
+			elems := make([]string, 0, len(list))
+			for _, elem := range list {
+				e, ok := formatScalar(elem)
+				if !ok {
+					return fmt.Errorf("key %q: expected a list of scalar values, got a list containing %s", key, describeValue(elem))
+				}
+				elems = append(elems, e)
+			}
+			s = strings.Join(elems, ",")
+		} else if s, ok = formatScalar(val); !ok {
+			return fmt.Errorf("key %q: unsupported value %s", key, describeValue(val))
+		}
+
+		if _, ok := params[key]; ok {
+			return fmt.Errorf("key %q: duplicate key after flattening", key)
+		}
+		params[key] = s
+	}
+	return nil
+}
+
+// formatScalar returns the given scalar value as a string. The return value ok is false if the value isn't a scalar.
+func formatScalar(val interface{}) (s string, ok bool) {
+	switch v := val.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	}
+	return "", false
+}
+
+// describeValue returns a description of the type of the given value, for use in error messages.
+func describeValue(val interface{}) string {
+	switch val.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("a value of type %T", val)
+}
@@ -0,0 +1,292 @@
+package configurama
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// parseSimple is a minimal parser for tests, it parses "[section]" headers and "key=value" lines.
+func parseSimple(r io.Reader) (map[string]map[string]string, error) {
+	params := make(map[string]map[string]string)
+	var section string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.Trim(line, "[]")
+			params[section] = make(map[string]string)
+		default:
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 || params[section] == nil {
+				return nil, errors.New("invalid line: " + line)
+			}
+			params[section][parts[0]] = parts[1]
+		}
+	}
+	return params, scanner.Err()
+}
+
+func TestNewFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/default.conf": {Data: []byte("[db]\nhost=localhost\nport=3306\n")},
+		"config/broken.conf":  {Data: []byte("host=localhost\n")},
+	}
+
+	t.Run("valid file", func(t *testing.T) {
+		p, err := NewFromFS(fsys, "config/default.conf", parseSimple)
+		verifyNil(t, err)
+		verifyEqual(t, map[string]map[string]string{"db": {"host": "localhost", "port": "3306"}}, p.Raw())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewFromFS(fsys, "config/missing.conf", parseSimple)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected fs.ErrNotExist, got %v", err)
+		}
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		_, err := NewFromFS(fsys, "config/broken.conf", parseSimple)
+		if err == nil || !strings.Contains(err.Error(), "config/broken.conf") {
+			t.Errorf("expected parse error mentioning the path, got %v", err)
+		}
+	})
+}
+
+func TestResolveFileRefs(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token := filepath.Join(dir, "token")
+	if err := os.WriteFile(token, []byte("  abc  "), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("files exist", func(t *testing.T) {
+		c := New(map[string]map[string]string{
+			"db":  {"user": "root", "password_file": secret},
+			"api": {"token_file": token, "token": "overwritten"},
+		})
+		verifyNil(t, c.ResolveFileRefs("_file"))
+		verifyEqual(t, map[string]map[string]string{
+			"db":  {"user": "root", "password": "s3cr3t"},
+			"api": {"token": "abc"},
+		}, c.Raw())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		params := map[string]map[string]string{
+			"db":  {"password_file": secret},
+			"api": {"token_file": filepath.Join(dir, "missing")},
+		}
+		c := New(copyParams(params))
+		err := c.ResolveFileRefs("_file")
+		var multi MultiError
+		if !errors.As(err, &multi) || len(multi) != 1 || !errors.Is(multi[0], fs.ErrNotExist) {
+			t.Errorf("expected a MultiError containing fs.ErrNotExist, got %v", err)
+		}
+		verifyEqual(t, params, c.Raw())
+	})
+}
+
+func TestMergeGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-base.conf":     "[db]\nhost=localhost\nport=3306\n",
+		"20-override.conf": "[db]\nhost=db.example.com\n[cache]\nttl=1m\n",
+		"notes.txt":        "not a config file",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pattern := filepath.Join(dir, "*.conf")
+
+	t.Run("overwrite", func(t *testing.T) {
+		p, err := MergeGlob(pattern, parseSimple, Overwrite)
+		verifyNil(t, err)
+		verifyEqual(t, map[string]map[string]string{
+			"db":    {"host": "db.example.com", "port": "3306"},
+			"cache": {"ttl": "1m"},
+		}, p.Raw())
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		p, err := MergeGlob(pattern, parseSimple, Keep)
+		verifyNil(t, err)
+		verifyEqual(t, map[string]map[string]string{
+			"db":    {"host": "localhost", "port": "3306"},
+			"cache": {"ttl": "1m"},
+		}, p.Raw())
+	})
+
+	t.Run("report", func(t *testing.T) {
+		_, err := MergeGlob(pattern, parseSimple, Report)
+		if err == nil || !strings.Contains(err.Error(), "20-override.conf") {
+			t.Errorf("expected conflict error mentioning the file, got %v", err)
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		p, err := MergeGlob(filepath.Join(dir, "*.ini"), parseSimple, Report)
+		verifyNil(t, err)
+		verifyEqual(t, empty, p.Raw())
+	})
+}
+
+func TestLoadJSON(t *testing.T) {
+	t.Run("valid JSON", func(t *testing.T) {
+		p, err := LoadJSON(strings.NewReader(`{
+			"db": {
+				"host": "localhost",
+				"port": 3306,
+				"ratio": 0.5,
+				"debug": true,
+				"password": null,
+				"tags": ["a", 1, false],
+				"master": {"host": "master.local", "replica": {"host": "replica.local"}}
+			},
+			"cache": {}
+		}`))
+		verifyNil(t, err)
+		verifyEqual(t, p.Raw(), map[string]map[string]string{
+			"db": {
+				"host":                "localhost",
+				"port":                "3306",
+				"ratio":               "0.5",
+				"debug":               "true",
+				"password":            "",
+				"tags":                "a,1,false",
+				"master.host":         "master.local",
+				"master.replica.host": "replica.local",
+			},
+			"cache": {},
+		})
+	})
+
+	tt := map[string]string{
+		"malformed":                  `{"db": `,
+		"not an object":              `["db"]`,
+		"null":                       `null`,
+		"trailing object":            `{"db": {}} {"cache": {}}`,
+		"trailing garbage":           `{"db": {}} x`,
+		"section not object":         `{"db": "localhost"}`,
+		"list of objects":            `{"db": {"hosts": [{"host": "a"}]}}`,
+		"duplicate after flattening": `{"db": {"master.host": "a", "master": {"host": "b"}}}`,
+	}
+
+	for name, data := range tt {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadJSON(strings.NewReader(data)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseINI(t *testing.T) {
+	params, err := ParseINI(strings.NewReader(`; Database settings
+[db]
+host = localhost
+port=3306
+motd = "hello\nworld"
+url = https://example.com/?a=b
+
+# Empty section
+[cache]
+`))
+	verifyNil(t, err)
+	verifyEqual(t, params, map[string]map[string]string{
+		"db": {
+			"host": "localhost",
+			"port": "3306",
+			"motd": "hello\nworld",
+			"url":  "https://example.com/?a=b",
+		},
+		"cache": {},
+	})
+
+	t.Run("colon separator", func(t *testing.T) {
+		params, err := ParseINI(strings.NewReader("[db]\nhost: localhost\nurl: http://localhost:8080\nquery = a:b\n"))
+		verifyNil(t, err)
+		verifyEqual(t, params, map[string]map[string]string{
+			"db": {"host": "localhost", "url": "http://localhost:8080", "query": "a:b"},
+		})
+	})
+
+	t.Run("pretty printed", func(t *testing.T) {
+		expected := map[string]map[string]string{
+			"db":    {"host": "localhost", "motd": "hello\nworld", "padded": " x ", "empty": ""},
+			"cache": {"ttl": "1m"},
+		}
+		params, err := ParseINI(strings.NewReader(MustPrettyPrint(expected, "  ", Quote(QuoteAmbiguous))))
+		verifyNil(t, err)
+		verifyEqual(t, params, expected)
+	})
+
+	t.Run("long lines", func(t *testing.T) {
+		long := strings.Repeat("x", 1<<20)
+		params, err := ParseINI(strings.NewReader("[db]\ncert = " + long + "\n"))
+		verifyNil(t, err)
+		if params["db"]["cert"] != long {
+			t.Errorf("expected value of length %d, got length %d", len(long), len(params["db"]["cert"]))
+		}
+	})
+
+	tt := map[string]string{
+		"key outside section": "host = localhost\n",
+		"malformed line":      "[db]\nhost\n",
+		"invalid quotes":      "[db]\nhost = \"localhost\n",
+		"duplicate key":       "[db]\nhost = a\nhost = b\n",
+	}
+
+	for name, data := range tt {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseINI(strings.NewReader(data)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadINI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	contents := "[zeta]\nkey = z\n\n[alpha]\nkey = a\n\n[mid]\nkey = m\n\n[zeta]\nother = z\n"
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	p, err := LoadINI(f)
+	verifyNil(t, err)
+	verifyEqual(t, p.Raw(), map[string]map[string]string{
+		"zeta":  {"key": "z", "other": "z"},
+		"alpha": {"key": "a"},
+		"mid":   {"key": "m"},
+	})
+	if actual, expected := p.OrderedSections(), []string{"zeta", "alpha", "mid"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected sections %v, got %v", expected, actual)
+	}
+
+	if _, err = LoadINI(strings.NewReader("key = value\n")); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
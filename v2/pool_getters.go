@@ -0,0 +1,398 @@
+package configurama
+
+import (
+	"net"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// The pool-level getters below behave like their Params counterparts, except that they look up the section
+// by name on every call. Unlike Params, which is detached from the pool, they take pool-level settings such as
+// validation tracing and access tracking into account. A missing section is treated the same way as a missing key.
+
+// String returns the string value for the given key in the given section, see Params.String.
+func (p *Pool) String(section, key string, options ...Option) (string, error) {
+	sec, options, done := p.lookup("String", section, key, options)
+	defer done()
+	return sec.String(key, options...)
+}
+
+// Strings returns the string values for the given key in the given section, see Params.Strings.
+func (p *Pool) Strings(section, key, separator string, options ...Option) ([]string, error) {
+	sec, options, done := p.lookup("Strings", section, key, options)
+	defer done()
+	return sec.Strings(key, separator, options...)
+}
+
+// HostPorts returns the value for the given key in the given section as "host:port" endpoints, see Params.HostPorts.
+func (p *Pool) HostPorts(section, key, separator string, options ...Option) ([]string, error) {
+	sec, options, done := p.lookup("HostPorts", section, key, options)
+	defer done()
+	return sec.HostPorts(key, separator, options...)
+}
+
+// StringMap returns the value for the given key in the given section as a map, see Params.StringMap.
+func (p *Pool) StringMap(section, key, pairSep, kvSep string, options ...Option) (map[string]string, error) {
+	sec, options, done := p.lookup("StringMap", section, key, options)
+	defer done()
+	return sec.StringMap(key, pairSep, kvSep, options...)
+}
+
+// Slug returns the value for the given key in the given section normalized to a slug, see Params.Slug.
+func (p *Pool) Slug(section, key string, options ...Option) (string, error) {
+	sec, options, done := p.lookup("Slug", section, key, options)
+	defer done()
+	return sec.Slug(key, options...)
+}
+
+// UUID returns the value for the given key in the given section as a normalized UUID, see Params.UUID.
+func (p *Pool) UUID(section, key string, options ...Option) (string, error) {
+	sec, options, done := p.lookup("UUID", section, key, options)
+	defer done()
+	return sec.UUID(key, options...)
+}
+
+// URL returns the value for the given key in the given section as an absolute URL, see Params.URL.
+func (p *Pool) URL(section, key string, options ...Option) (*url.URL, error) {
+	sec, options, done := p.lookup("URL", section, key, options)
+	defer done()
+	return sec.URL(key, options...)
+}
+
+// IP returns the value for the given key in the given section as an IP address, see Params.IP.
+func (p *Pool) IP(section, key string, options ...Option) (net.IP, error) {
+	sec, options, done := p.lookup("IP", section, key, options)
+	defer done()
+	return sec.IP(key, options...)
+}
+
+// LanguageTag returns the value for the given key in the given section as a canonical BCP 47 language tag,
+// see Params.LanguageTag.
+func (p *Pool) LanguageTag(section, key string, options ...Option) (string, error) {
+	sec, options, done := p.lookup("LanguageTag", section, key, options)
+	defer done()
+	return sec.LanguageTag(key, options...)
+}
+
+// Pair returns the value for the given key in the given section split into two parts, see Params.Pair.
+func (p *Pool) Pair(section, key, separator string, options ...Option) (first, second string, err error) {
+	sec, options, done := p.lookup("Pair", section, key, options)
+	defer done()
+	return sec.Pair(key, separator, options...)
+}
+
+// Triple returns the value for the given key in the given section split into three parts, see Params.Triple.
+func (p *Pool) Triple(section, key, separator string, options ...Option) (first, second, third string, err error) {
+	sec, options, done := p.lookup("Triple", section, key, options)
+	defer done()
+	return sec.Triple(key, separator, options...)
+}
+
+// Int returns the value for the given key in the given section as an int, see Params.Int.
+func (p *Pool) Int(section, key string, options ...Option) (int, error) {
+	sec, options, done := p.lookup("Int", section, key, options)
+	defer done()
+	return sec.Int(key, options...)
+}
+
+// Int8 returns the value for the given key in the given section as an int8, see Params.Int8.
+func (p *Pool) Int8(section, key string, options ...Option) (int8, error) {
+	sec, options, done := p.lookup("Int8", section, key, options)
+	defer done()
+	return sec.Int8(key, options...)
+}
+
+// Int16 returns the value for the given key in the given section as an int16, see Params.Int16.
+func (p *Pool) Int16(section, key string, options ...Option) (int16, error) {
+	sec, options, done := p.lookup("Int16", section, key, options)
+	defer done()
+	return sec.Int16(key, options...)
+}
+
+// Int32 returns the value for the given key in the given section as an int32, see Params.Int32.
+func (p *Pool) Int32(section, key string, options ...Option) (int32, error) {
+	sec, options, done := p.lookup("Int32", section, key, options)
+	defer done()
+	return sec.Int32(key, options...)
+}
+
+// Int64 returns the value for the given key in the given section as an int64, see Params.Int64.
+func (p *Pool) Int64(section, key string, options ...Option) (int64, error) {
+	sec, options, done := p.lookup("Int64", section, key, options)
+	defer done()
+	return sec.Int64(key, options...)
+}
+
+// IntBase returns the value for the given key in the given section as an int64 in the given base, see Params.IntBase.
+func (p *Pool) IntBase(section, key string, base int, options ...Option) (int64, error) {
+	sec, options, done := p.lookup("IntBase", section, key, options)
+	defer done()
+	return sec.IntBase(key, base, options...)
+}
+
+// Port returns the value for the given key in the given section as a port number, see Params.Port.
+func (p *Pool) Port(section, key string, options ...Option) (uint16, error) {
+	sec, options, done := p.lookup("Port", section, key, options)
+	defer done()
+	return sec.Port(key, options...)
+}
+
+// Uint8 returns the value for the given key in the given section as a uint8, see Params.Uint8.
+func (p *Pool) Uint8(section, key string, options ...Option) (uint8, error) {
+	sec, options, done := p.lookup("Uint8", section, key, options)
+	defer done()
+	return sec.Uint8(key, options...)
+}
+
+// Uint16 returns the value for the given key in the given section as a uint16, see Params.Uint16.
+func (p *Pool) Uint16(section, key string, options ...Option) (uint16, error) {
+	sec, options, done := p.lookup("Uint16", section, key, options)
+	defer done()
+	return sec.Uint16(key, options...)
+}
+
+// Uint32 returns the value for the given key in the given section as a uint32, see Params.Uint32.
+func (p *Pool) Uint32(section, key string, options ...Option) (uint32, error) {
+	sec, options, done := p.lookup("Uint32", section, key, options)
+	defer done()
+	return sec.Uint32(key, options...)
+}
+
+// Uint64 returns the value for the given key in the given section as a uint64, see Params.Uint64.
+func (p *Pool) Uint64(section, key string, options ...Option) (uint64, error) {
+	sec, options, done := p.lookup("Uint64", section, key, options)
+	defer done()
+	return sec.Uint64(key, options...)
+}
+
+// Float returns the value for the given key in the given section as a float64, see Params.Float.
+func (p *Pool) Float(section, key string, options ...Option) (float64, error) {
+	sec, options, done := p.lookup("Float", section, key, options)
+	defer done()
+	return sec.Float(key, options...)
+}
+
+// Bytes returns the value for the given key in the given section as a number of bytes, see Params.Bytes.
+func (p *Pool) Bytes(section, key string, options ...Option) (int64, error) {
+	sec, options, done := p.lookup("Bytes", section, key, options)
+	defer done()
+	return sec.Bytes(key, options...)
+}
+
+// Bool returns the value for the given key in the given section as a bool, see Params.Bool.
+func (p *Pool) Bool(section, key string, options ...Option) (bool, error) {
+	sec, options, done := p.lookup("Bool", section, key, options)
+	defer done()
+	return sec.Bool(key, options...)
+}
+
+// BoolPtr returns the value for the given key in the given section as a pointer to a bool, see Params.BoolPtr.
+func (p *Pool) BoolPtr(section, key string, options ...Option) (*bool, error) {
+	sec, options, done := p.lookup("BoolPtr", section, key, options)
+	defer done()
+	return sec.BoolPtr(key, options...)
+}
+
+// Duration returns the value for the given key in the given section as a time.Duration, see Params.Duration.
+func (p *Pool) Duration(section, key string, options ...Option) (time.Duration, error) {
+	sec, options, done := p.lookup("Duration", section, key, options)
+	defer done()
+	return sec.Duration(key, options...)
+}
+
+// Time returns the value for the given key in the given section as a time.Time, see Params.Time.
+func (p *Pool) Time(section, key, format string, options ...Option) (time.Time, error) {
+	sec, options, done := p.lookup("Time", section, key, options)
+	defer done()
+	return sec.Time(key, format, options...)
+}
+
+// TimeMulti returns the value for the given key in the given section as a time.Time using the first matching format,
+// see Params.TimeMulti.
+func (p *Pool) TimeMulti(section, key string, formats []string, options ...Option) (time.Time, error) {
+	sec, options, done := p.lookup("TimeMulti", section, key, options)
+	defer done()
+	return sec.TimeMulti(key, formats, options...)
+}
+
+// RelativeTime returns the value for the given key in the given section as a time.Time relative to base,
+// see Params.RelativeTime.
+func (p *Pool) RelativeTime(section, key string, base time.Time, options ...Option) (time.Time, error) {
+	sec, options, done := p.lookup("RelativeTime", section, key, options)
+	defer done()
+	return sec.RelativeTime(key, base, options...)
+}
+
+// Deadline returns the value for the given key in the given section as a deadline relative to the current time,
+// see Params.Deadline.
+func (p *Pool) Deadline(section, key string, options ...Option) (time.Time, error) {
+	sec, options, done := p.lookup("Deadline", section, key, options)
+	defer done()
+	return sec.Deadline(key, options...)
+}
+
+// lookup prepares a call to the pool-level getter op for the given section and key. It returns a copy of the section
+// (nil if the section doesn't exist), the given options extended with any pool-level options, and a function
+// that must be called once the getter returns. If the key is empty and a FallbackSection is given, the value from
+// the fallback section is copied into the returned section. Otherwise, if the key is missing and a fallback section
+// is set via SetFallbackSection, the value from that section is copied instead. If a DefaultBySection is given with
+// a default for the section, it's added as a Default.
+func (p *Pool) lookup(op, section, key string, options []Option) (Params, []Option, func()) {
+	var start time.Time
+	hook, _ := p.metricsHook.Load().(func(op, section, key string, d time.Duration))
+	if hook != nil {
+		start = time.Now()
+	}
+
+	if keyOptions, _ := p.keyOptions.Load().(map[string]map[string]Option); keyOptions != nil {
+		if opt, ok := keyOptions[section][key]; ok {
+			options = append([]Option{opt}, options...)
+		}
+	}
+
+	p.recordAccess(section, key)
+	all := p.load()
+	var sec Params
+	if params, ok := all[section]; ok {
+		sec = make(Params, len(params))
+		for k, v := range params {
+			sec[k] = v
+		}
+	}
+
+	opt := newOption(options...)
+	fallback := p.fallbackSection()
+	if opt.fallback != nil && sec[key] == "" {
+		p.recordAccess(*opt.fallback, key)
+		if val := all[*opt.fallback][key]; val != "" {
+			if sec == nil {
+				sec = make(Params, 1)
+			}
+			sec[key] = val
+		}
+	} else if _, ok := sec[key]; !ok && opt.fallback == nil && fallback != "" {
+		p.recordAccess(fallback, key)
+		if val, ok := all[fallback][key]; ok {
+			if sec == nil {
+				sec = make(Params, 1)
+			}
+			sec[key] = val
+		}
+	}
+	if val, ok := opt.sectionDefaults[section]; ok {
+		options = append(options[:len(options):len(options)], Default(val))
+	}
+
+	var trace []string
+	if atomic.LoadUint32(&p.traceValidation) != 0 {
+		trace = make([]string, 0)
+		options = append(options[:len(options):len(options)], func(o *option) {
+			o.trace = func(name string, err error) {
+				outcome := "ok"
+				if err != nil {
+					outcome = "failed"
+				}
+				trace = append(trace, name+":"+outcome)
+			}
+		})
+	}
+
+	done := func() {
+		if trace != nil {
+			p.storeTrace(section, key, trace)
+		}
+		if hook != nil {
+			hook(op, section, key, time.Since(start))
+		}
+	}
+
+	return sec, options, done
+}
+
+// SetMetricsHook sets a function that is called after every call to a pool-level getter, with the name of the
+// getter, i.e. "Int" or "Duration", the section and key, and the time it took. This makes it possible to collect
+// metrics, i.e. via Prometheus or OpenTelemetry, without depending on a metrics library. Getters don't measure
+// anything while no hook is set. Pass nil to remove the hook.
+func (p *Pool) SetMetricsHook(fn func(op, section, key string, d time.Duration)) {
+	p.metricsHook.Store(fn)
+}
+
+// SetFallbackSection sets the name of a section to consult for keys that are missing from the requested section,
+// i.e. a "default" section with values that apply unless a more specific section overrides them. It's honored by
+// Get, Params and the pool-level getters, which treat a missing section like a section without keys. A
+// FallbackSection passed to a getter takes precedence. The fallback section is only used for missing keys, never to
+// override values that are present, even if they're empty. Pass an empty name to remove the fallback section.
+func (p *Pool) SetFallbackSection(name string) {
+	p.fallback.Store(name)
+}
+
+// fallbackSection returns the name of the section set via SetFallbackSection, or an empty string if none is set.
+func (p *Pool) fallbackSection() string {
+	name, _ := p.fallback.Load().(string)
+	return name
+}
+
+// SetKeyOptions sets options that the pool-level getters apply to the given key in the given section, in addition
+// to the options passed to the getter, i.e. to keep validation rules for a key in one place rather than repeating
+// them at every call site. The options are applied before the options passed to the getter, so validators set here
+// run first, while i.e. a Default passed to the getter takes precedence. The options are resolved once, when they
+// are set, rather than on every call. Calling SetKeyOptions without options removes any options for the key.
+func (p *Pool) SetKeyOptions(section, key string, options ...Option) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The options are replaced as a whole, so that lookup can read them without locking.
+	current, _ := p.keyOptions.Load().(map[string]map[string]Option)
+	next := make(map[string]map[string]Option, len(current))
+	for sec, keys := range current {
+		next[sec] = keys
+	}
+	keys := make(map[string]Option, len(next[section])+1)
+	for k, opt := range next[section] {
+		keys[k] = opt
+	}
+	if len(options) == 0 {
+		delete(keys, key)
+	} else {
+		keys[key] = resolvedOption(newOption(options...))
+	}
+	next[section] = keys
+	p.keyOptions.Store(next)
+}
+
+// EnableValidationTrace enables recording of which validation options ran for each key, and their outcome.
+// Only pool-level getters record traces, since Params are detached from the pool.
+// Traces can be retrieved via ValidationTrace.
+func (p *Pool) EnableValidationTrace() {
+	atomic.StoreUint32(&p.traceValidation, 1)
+}
+
+// ValidationTrace returns the validation trace for the given key in the given section, as recorded by the most
+// recent pool-level getter call for that key. Each entry consists of the name of a validation option and its
+// outcome, i.e. "enum:ok" or "regexp:failed". Validation stops at the first failure, so validators that didn't
+// run are not listed. Nil is returned if the key hasn't been fetched since validation tracing was enabled.
+func (p *Pool) ValidationTrace(section, key string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	trace, ok := p.traces[section][key]
+	if !ok {
+		return nil
+	}
+	return append([]string{}, trace...)
+}
+
+// storeTrace stores the given validation trace for the given key in the given section.
+func (p *Pool) storeTrace(section, key string, trace []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.traces == nil {
+		p.traces = make(map[string]map[string][]string)
+	}
+	if p.traces[section] == nil {
+		p.traces[section] = make(map[string][]string)
+	}
+	p.traces[section][key] = trace
+}
@@ -0,0 +1,332 @@
+package configurama
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestPoolGetters(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"dev": {
+			"host":    "localhost",
+			"port":    "3306",
+			"ratio":   "0.5",
+			"debug":   "on",
+			"timeout": "5s",
+			"tags":    "a,b",
+		},
+	})
+
+	host, err := c.String("dev", "host", Require())
+	verifyNil(t, err)
+	port, err := c.Uint16("dev", "port")
+	verifyNil(t, err)
+	ratio, err := c.Float("dev", "ratio")
+	verifyNil(t, err)
+	debug, err := c.Bool("dev", "debug")
+	verifyNil(t, err)
+	timeout, err := c.Duration("dev", "timeout")
+	verifyNil(t, err)
+	tags, err := c.Strings("dev", "tags", ",")
+	verifyNil(t, err)
+
+	if host != "localhost" || port != 3306 || ratio != 0.5 || !debug || timeout != 5*time.Second || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("unexpected values: %q, %d, %f, %t, %s, %v", host, port, ratio, debug, timeout, tags)
+	}
+
+	if _, err = c.Int("unknown", "port", Require()); err != NoKeyError("port") {
+		t.Errorf("expected error %v for unknown section, got %v", NoKeyError("port"), err)
+	}
+	if port, err := c.Int("unknown", "port", Default("80")); err != nil || port != 80 {
+		t.Errorf("expected default value for unknown section, got %d, %v", port, err)
+	}
+}
+
+func TestFallbackSection(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"default": {
+			"host": "localhost",
+			"port": "3306",
+		},
+		"dev": {
+			"host": "dev.local",
+			"port": "",
+		},
+	})
+
+	tt := map[string]struct {
+		section, key string
+		options      []Option
+		expected     string
+		err          error
+	}{
+		"own value": {
+			"dev", "host", []Option{FallbackSection("default")}, "dev.local", nil,
+		},
+		"empty value": {
+			"dev", "port", []Option{FallbackSection("default"), Default("80")}, "3306", nil,
+		},
+		"missing section": {
+			"prod", "host", []Option{FallbackSection("default"), Require()}, "localhost", nil,
+		},
+		"missing in fallback": {
+			"dev", "user", []Option{FallbackSection("default"), Default("root")}, "root", nil,
+		},
+		"missing in fallback, required": {
+			"dev", "user", []Option{FallbackSection("default"), Require()}, "", NoKeyError("user"),
+		},
+		"unknown fallback section": {
+			"dev", "port", []Option{FallbackSection("unknown")}, "", nil,
+		},
+		"without fallback": {
+			"dev", "port", []Option{}, "", nil,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := c.String(tc.section, tc.key, tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+
+	// Params are detached from the pool, so the fallback section is ignored.
+	dev, _ := c.Params("dev")
+	if port, _ := dev.String("port", FallbackSection("default")); port != "" {
+		t.Errorf("expected fallback section to be ignored by Params, got %q", port)
+	}
+}
+
+func TestSetFallbackSection(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"default": {
+			"host": "localhost",
+			"port": "3306",
+			"user": "root",
+		},
+		"dev": {
+			"host": "dev.local",
+			"port": "",
+		},
+		"other": {
+			"user": "admin",
+		},
+	})
+	c.SetFallbackSection("default")
+
+	if host, err := c.String("dev", "host"); err != nil || host != "dev.local" {
+		t.Errorf("expected own value %q, got %q, %v", "dev.local", host, err)
+	}
+	if port, err := c.Int("dev", "port"); err != nil || port != 0 {
+		t.Errorf("expected own empty value, got %d, %v", port, err)
+	}
+	if user, err := c.String("dev", "user", Require()); err != nil || user != "root" {
+		t.Errorf("expected fallback value %q, got %q, %v", "root", user, err)
+	}
+	if host, err := c.String("prod", "host"); err != nil || host != "localhost" {
+		t.Errorf("expected fallback value for missing section %q, got %q, %v", "localhost", host, err)
+	}
+	if user, err := c.String("dev", "user", FallbackSection("other")); err != nil || user != "admin" {
+		t.Errorf("expected FallbackSection option to take precedence, got %q, %v", user, err)
+	}
+
+	if host, ok := c.Get("dev", "host"); !ok || host != "dev.local" {
+		t.Errorf("expected Get to return own value %q, got %q", "dev.local", host)
+	}
+	if port, ok := c.Get("dev", "port"); !ok || port != "" {
+		t.Errorf("expected Get to return own empty value, got %q", port)
+	}
+	if user, ok := c.Get("dev", "user"); !ok || user != "root" {
+		t.Errorf("expected Get to return fallback value %q, got %q", "root", user)
+	}
+	if _, ok := c.Get("dev", "password"); ok {
+		t.Error("expected Get to report key missing from both sections")
+	}
+
+	dev, _ := c.Params("dev")
+	verifyEqual(t, map[string]map[string]string{"dev": dev}, map[string]map[string]string{
+		"dev": {"host": "dev.local", "port": "", "user": "root"},
+	})
+	prod, ok := c.Params("prod")
+	if !ok {
+		t.Error("expected Params to use the fallback section for a missing section")
+	}
+	verifyEqual(t, map[string]map[string]string{"prod": prod}, map[string]map[string]string{
+		"prod": {"host": "localhost", "port": "3306", "user": "root"},
+	})
+
+	c.SetFallbackSection("")
+	if user, ok := c.Get("dev", "user"); ok {
+		t.Errorf("expected fallback section to be removed, got %q", user)
+	}
+	if _, ok := c.Params("prod"); ok {
+		t.Error("expected Params to report missing section without a fallback section")
+	}
+}
+
+func TestDefaultBySection(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"mysql":    {"host": "db1"},
+		"postgres": {"host": "db2", "port": "6432"},
+		"sqlite":   {},
+	})
+	defaults := DefaultBySection(map[string]string{"mysql": "3306", "postgres": "5432"})
+
+	tt := map[string]struct {
+		section  string
+		options  []Option
+		expected int
+		err      error
+	}{
+		"section default":                  {"mysql", []Option{defaults}, 3306, nil},
+		"own value":                        {"postgres", []Option{defaults}, 6432, nil},
+		"no section default":               {"sqlite", []Option{defaults}, 0, nil},
+		"no section default, default":      {"sqlite", []Option{defaults, Default("1")}, 1, nil},
+		"precedence over default":          {"mysql", []Option{Default("1"), defaults}, 3306, nil},
+		"missing section":                  {"unknown", []Option{defaults, Require()}, 0, NoKeyError("port")},
+		"section default, validated":       {"mysql", []Option{defaults, ValidateEnum([]string{"1"})}, 0, EnumValidationError("port")},
+		"section default, required":        {"mysql", []Option{defaults, Require()}, 0, NoKeyError("port")},
+		"section default, missing section": {"mssql", []Option{defaults}, 0, nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			actual, err := c.Int(tc.section, "port", tc.options...)
+			if err != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected value %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+
+	// Params are detached from the pool, so section defaults are ignored.
+	mysql, _ := c.Params("mysql")
+	if port, _ := mysql.String("port", defaults); port != "" {
+		t.Errorf("expected section defaults to be ignored by Params, got %q", port)
+	}
+}
+
+func TestSetKeyOptions(t *testing.T) {
+	c := New(map[string]map[string]string{"dev": {"mode": "fast", "port": "abc"}})
+	c.SetKeyOptions("dev", "mode", ValidateEnum([]string{"fast", "slow"}), Default("slow"))
+	c.SetKeyOptions("dev", "port", ValidateIntegral())
+	c.SetKeyOptions("dev", "host", Require())
+
+	if mode, err := c.String("dev", "mode"); err != nil || mode != "fast" {
+		t.Errorf("expected value %q, got %q, %v", "fast", mode, err)
+	}
+	if _, err := c.String("dev", "mode", ValidateEnum([]string{"slow"})); err != EnumValidationError("mode") {
+		t.Errorf("expected error %v, got %v", EnumValidationError("mode"), err)
+	}
+	if _, err := c.Int("dev", "port"); err != RegExpValidationError("port") {
+		t.Errorf("expected error %v, got %v", RegExpValidationError("port"), err)
+	}
+	if _, err := c.String("dev", "host"); err != NoKeyError("host") {
+		t.Errorf("expected error %v, got %v", NoKeyError("host"), err)
+	}
+
+	// Params are detached from the pool, so key options don't apply.
+	dev, _ := c.Params("dev")
+	if _, err := dev.String("host"); err != nil {
+		t.Errorf("expected key options to be ignored by Params, got %v", err)
+	}
+
+	c.SetKeyOptions("dev", "host")
+	if _, err := c.String("dev", "host"); err != nil {
+		t.Errorf("expected key options to be removed, got %v", err)
+	}
+
+	// Options passed to the getter take precedence over key options.
+	verifyNil(t, c.Set("dev", "mode", ""))
+	if mode, err := c.String("dev", "mode", Default("fast")); err != nil || mode != "fast" {
+		t.Errorf("expected value %q, got %q, %v", "fast", mode, err)
+	}
+	if mode, err := c.String("dev", "mode"); err != nil || mode != "slow" {
+		t.Errorf("expected value %q, got %q, %v", "slow", mode, err)
+	}
+}
+
+func TestMetricsHook(t *testing.T) {
+	c := New(map[string]map[string]string{"dev": {"port": "3306", "timeout": "5s"}})
+
+	calls := make([]string, 0)
+	c.SetMetricsHook(func(op, section, key string, d time.Duration) {
+		if d < 0 {
+			t.Errorf("expected non-negative duration, got %s", d)
+		}
+		calls = append(calls, op+":"+section+"."+key)
+	})
+
+	_, _ = c.Int("dev", "port")
+	_, _ = c.Duration("dev", "timeout")
+	_, _ = c.String("prod", "host")
+	if expected := []string{"Int:dev.port", "Duration:dev.timeout", "String:prod.host"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+
+	c.SetMetricsHook(nil)
+	_, _ = c.Int("dev", "port")
+	if len(calls) != 3 {
+		t.Errorf("expected no calls after removing the hook, got %v", calls[3:])
+	}
+}
+
+func TestValidationTrace(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"dev": {
+			"mode": "fast",
+			"port": "abc",
+		},
+	})
+	digits := regexp.MustCompile(`^[0-9]+$`)
+
+	// Nothing is recorded until tracing is enabled.
+	_, _ = c.String("dev", "mode", ValidateEnum([]string{"fast", "slow"}))
+	if trace := c.ValidationTrace("dev", "mode"); trace != nil {
+		t.Errorf("expected no trace before enabling, got %v", trace)
+	}
+
+	c.EnableValidationTrace()
+
+	tt := map[string]struct {
+		key      string
+		options  []Option
+		expected []string
+	}{
+		"all validators pass": {
+			"mode", []Option{ValidateLength(1, 10), ValidateRegExp(regexp.MustCompile(`^[a-z]+$`))}, []string{"length:ok", "regexp:ok"},
+		},
+		"validation fails": {
+			"port", []Option{ValidateRegExp(digits), ValidateLength(1, 10)}, []string{"regexp:failed"},
+		},
+		"regexp takes priority": {
+			"mode", []Option{ValidateEnum([]string{"slow"}), ValidateRegExp(regexp.MustCompile(`^[a-z]+$`))}, []string{"regexp:ok"},
+		},
+		"no validators": {
+			"mode", nil, []string{},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, _ = c.String("dev", tc.key, tc.options...)
+			actual := c.ValidationTrace("dev", tc.key)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected trace %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if trace := c.ValidationTrace("dev", "unknown"); trace != nil {
+		t.Errorf("expected no trace for unknown key, got %v", trace)
+	}
+}
@@ -0,0 +1,43 @@
+package configurama
+
+// EnableProvenance enables recording of the source of each key's value, which is useful for finding out where a
+// value came from when a configuration pool is assembled from multiple sources, i.e. defaults, files, environment
+// variables and flags. Sources are recorded by MergeFrom and SetFrom, and can be retrieved via Source.
+// Writes that don't specify a source, i.e. via Merge, Set or CompareAndSwap, remove the recorded source of the keys
+// they set, and so does removing keys via Unset. The same goes for methods rewriting values in place, i.e.
+// ExpandEnv, RenderTemplates, ResolveSecrets, ResolveFileRefs and MapValuesConcurrent, for the keys they rewrite.
+// Sources are only recorded for writes after provenance is enabled.
+func (p *Pool) EnableProvenance() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sources == nil {
+		p.sources = make(map[string]map[string]string)
+	}
+}
+
+// Source returns the source recorded by the write that last set the given key in the given section.
+// The return value ok is false if no source is recorded for the key, or provenance is not enabled.
+func (p *Pool) Source(section, key string) (source string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	source, ok = p.sources[section][key]
+	return
+}
+
+// recordSource records the given source for the given key in the given section, if provenance is enabled.
+// An empty source removes the recorded source. The caller must hold the lock.
+func (p *Pool) recordSource(section, key, source string) {
+	if p.sources == nil {
+		return
+	}
+	if source == "" {
+		delete(p.sources[section], key)
+		return
+	}
+	if p.sources[section] == nil {
+		p.sources[section] = make(map[string]string)
+	}
+	p.sources[section][key] = source
+}
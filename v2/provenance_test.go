@@ -0,0 +1,99 @@
+package configurama
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	c := New(map[string]map[string]string{"db": {"host": "localhost"}})
+	c.EnableProvenance()
+
+	if _, ok := c.Source("db", "host"); ok {
+		t.Error("expected no source for keys set before provenance was enabled")
+	}
+
+	verifyNil(t, c.MergeFrom("defaults", map[string]map[string]string{"db": {"host": "default", "port": "3306", "user": "root"}}, Keep))
+	verifyNil(t, c.MergeFrom("file", map[string]map[string]string{"db": {"port": "3307"}, "cache": {"ttl": "5m"}}, Overwrite))
+	verifyNil(t, c.SetFrom("flags", "db", "user", "admin"))
+
+	tt := map[string]struct {
+		section, key, expected string
+		ok                     bool
+	}{
+		"kept value":   {"db", "host", "", false},
+		"merged value": {"db", "port", "file", true},
+		"new section":  {"cache", "ttl", "file", true},
+		"set value":    {"db", "user", "flags", true},
+		"unknown key":  {"db", "unknown", "", false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			source, ok := c.Source(tc.section, tc.key)
+			if ok != tc.ok || source != tc.expected {
+				t.Errorf("expected source %q, %t, got %q, %t", tc.expected, tc.ok, source, ok)
+			}
+		})
+	}
+
+	// Writes without a source remove the recorded source.
+	verifyNil(t, c.Set("db", "user", "guest"))
+	if _, ok := c.Source("db", "user"); ok {
+		t.Error("expected no source after Set")
+	}
+	c.Unset("cache", "")
+	verifyNil(t, c.Merge(map[string]map[string]string{"cache": {}}, Overwrite))
+	if _, ok := c.Source("cache", "ttl"); ok {
+		t.Error("expected no source after Unset")
+	}
+
+	if err := c.MergeFrom("file", map[string]map[string]string{"db": {"port": "1"}}, Report); err == nil {
+		t.Error("expected error for conflicting key, got nil")
+	}
+	if source, _ := c.Source("db", "port"); source != "file" {
+		t.Errorf("expected source %q to be kept after failed merge, got %q", "file", source)
+	}
+}
+
+func TestProvenanceRewrites(t *testing.T) {
+	c := New(map[string]map[string]string{})
+	c.EnableProvenance()
+	verifyNil(t, c.MergeFrom("file", map[string]map[string]string{"app": {
+		"host":     "localhost",
+		"url":      "http://{{.host}}/",
+		"password": SecretPrefix + "db",
+		"home":     "${CONFIGURAMA_UNSET_VAR:-/home}",
+	}}, Overwrite))
+	c.SetSecretResolver(func(ref string) (string, error) { return "secret", nil })
+
+	verifyNil(t, c.RenderTemplates())
+	verifyNil(t, c.ResolveSecrets())
+	c.ExpandEnv()
+
+	tt := map[string]struct {
+		key string
+		ok  bool
+	}{
+		"untouched":       {"host", true},
+		"rendered":        {"url", false},
+		"resolved secret": {"password", false},
+		"expanded":        {"home", false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if source, ok := c.Source("app", tc.key); ok != tc.ok {
+				t.Errorf("expected source to be recorded: %t, got %q, %t", tc.ok, source, ok)
+			}
+		})
+	}
+
+	verifyNil(t, c.MapValuesConcurrent(context.Background(), 2, func(section, key, value string) (string, error) {
+		return strings.ToUpper(value), nil
+	}))
+	if source, ok := c.Source("app", "host"); ok {
+		t.Errorf("expected no source after MapValuesConcurrent, got %q", source)
+	}
+}
@@ -0,0 +1,72 @@
+package configurama
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RefKind represents the kind of name a reference refers to, identified by the constants below.
+type RefKind uint8
+
+const (
+	// RefSection indicates that a reference names a section.
+	RefSection RefKind = iota
+
+	// RefKey indicates that a reference names a key in the section TargetSection.
+	RefKey
+)
+
+// Ref declares that the value for Key in Section must name an existing section or key, depending on TargetKind.
+// The name is the value prefixed with TargetPrefix, i.e. a value "primary" with the prefix "db." refers to the
+// section "db.primary" when TargetKind is RefSection. TargetSection is the section containing the referenced keys
+// when TargetKind is RefKey, and is ignored otherwise.
+// If Separator is non-empty, the value is treated as a list and each element must name an existing section or key.
+type Ref struct {
+	Section, Key  string
+	TargetKind    RefKind
+	TargetPrefix  string
+	TargetSection string
+	Separator     string
+}
+
+// ValidateReferences validates every given reference against the configuration pool, catching references that were
+// broken by renaming or removing the section or key they refer to. References whose key doesn't exist, or whose
+// value is empty, are skipped; use Require or RequireSections to check for their presence.
+// The returned error is a MultiError containing a ReferenceError for each dangling reference, in the order given,
+// each wrapped with the name of the section containing the reference.
+func (p *Pool) ValidateReferences(refs []Ref) error {
+	params := p.load()
+	errs := make([]error, 0)
+	for _, ref := range refs {
+		val := params[ref.Section][ref.Key]
+		if val == "" {
+			continue
+		}
+		names := []string{val}
+		if ref.Separator != "" {
+			names = strings.Split(val, ref.Separator)
+		}
+
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			var ok bool
+			var target string
+			switch ref.TargetKind {
+			case RefSection:
+				_, ok = params[ref.TargetPrefix+name]
+				target = "section"
+			case RefKey:
+				_, ok = params[ref.TargetSection][ref.TargetPrefix+name]
+				target = fmt.Sprintf("key in section %q", ref.TargetSection)
+			}
+			if !ok {
+				refErr := ReferenceError{ref.Key, ref.TargetPrefix + name, target}
+				errs = append(errs, fmt.Errorf("section %q: %w", ref.Section, refErr))
+			}
+		}
+	}
+	return multiError(errs)
+}
@@ -0,0 +1,56 @@
+package configurama
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateReferences(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"app": {
+			"database":        "primary",
+			"replicas":        "replica1, replica2",
+			"defaultProfile":  "fast",
+			"fallbackProfile": "unknown",
+			"empty":           "",
+		},
+		"db.primary":  {"host": "db1"},
+		"db.replica1": {"host": "db2"},
+		"profiles": {
+			"fast": "1",
+			"slow": "2",
+		},
+	})
+
+	refs := []Ref{
+		{Section: "app", Key: "database", TargetKind: RefSection, TargetPrefix: "db."},
+		{Section: "app", Key: "replicas", TargetKind: RefSection, TargetPrefix: "db.", Separator: ","},
+		{Section: "app", Key: "defaultProfile", TargetKind: RefKey, TargetSection: "profiles"},
+		{Section: "app", Key: "fallbackProfile", TargetKind: RefKey, TargetSection: "profiles"},
+		{Section: "app", Key: "empty", TargetKind: RefSection},
+		{Section: "app", Key: "unknown", TargetKind: RefSection},
+		{Section: "unknown", Key: "database", TargetKind: RefSection},
+	}
+
+	expected := []ReferenceError{
+		{"replicas", "db.replica2", "section"},
+		{"fallbackProfile", "unknown", `key in section "profiles"`},
+	}
+
+	err := c.ValidateReferences(refs)
+	var multiErr MultiError
+	if !errors.As(err, &multiErr) || len(multiErr) != len(expected) {
+		t.Fatalf("expected MultiError with %d errors, got %v", len(expected), err)
+	}
+	for i, exp := range expected {
+		var refErr ReferenceError
+		if !errors.As(multiErr[i], &refErr) || refErr != exp {
+			t.Errorf("expected error %v, got %v", exp, multiErr[i])
+		}
+	}
+	if s := multiErr[0].Error(); s != `section "app": value "db.replica2" for key "replicas" does not refer to an existing section` {
+		t.Errorf("unexpected error message: %s", s)
+	}
+
+	verifyNil(t, c.ValidateReferences([]Ref{refs[0], refs[2], refs[4]}))
+}
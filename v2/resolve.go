@@ -0,0 +1,30 @@
+package configurama
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// ValidateResolvable validates a parameter as a host name that resolves to at least one address, using
+// net.DefaultResolver. Validation fails if the name doesn't resolve within the given timeout, in which case a
+// ResolveValidationError is returned containing the reason. IP addresses are accepted without any lookup.
+// Note that this performs network I/O every time the parameter is fetched, and that the outcome depends on the
+// environment, so it should preferably be used for parameters that are fetched once, i.e. at startup.
+var ValidateResolvable = func(timeout time.Duration) Option {
+	return func(o *option) {
+		o.validate("resolvable", func(key, value string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			addrs, err := net.DefaultResolver.LookupHost(ctx, value)
+			if err != nil {
+				return ResolveValidationError{key, err.Error()}
+			}
+			if len(addrs) == 0 {
+				return ResolveValidationError{key, "no addresses found"}
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package configurama
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateResolvable(t *testing.T) {
+	_, err := checkApplyOptions("x", "127.0.0.1", true, ValidateResolvable(time.Second))
+	verifyNil(t, err)
+
+	// The lookup can't complete within the timeout, so this doesn't depend on the network.
+	_, err = checkApplyOptions("x", "configurama.invalid", true, ValidateResolvable(time.Nanosecond))
+	var resolveErr ResolveValidationError
+	if !errors.As(err, &resolveErr) {
+		t.Errorf("expected ResolveValidationError, got %v", err)
+	}
+}
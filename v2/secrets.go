@@ -0,0 +1,80 @@
+package configurama
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// SecretPrefix is the prefix of values that refer to secrets, i.e. "secret://db/password".
+const SecretPrefix = "secret://"
+
+// errNoSecretResolver is returned for secret references when no secret resolver has been set.
+var errNoSecretResolver = errors.New("no secret resolver set")
+
+// SetSecretResolver sets the function used by ResolveSecrets to fetch secrets, i.e. from Vault or AWS SSM.
+// The function is called with the reference of a secret, which is the value without SecretPrefix, i.e.
+// "db/password" for the value "secret://db/password", and must return the secret.
+func (p *Pool) SetSecretResolver(fn func(ref string) (string, error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.secretResolver = fn
+}
+
+// ResolveSecrets replaces every value that starts with SecretPrefix with the secret it refers to, as returned by
+// the function set via SetSecretResolver. This keeps secrets out of configuration files without tying the package
+// to a specific secrets provider.
+// All secrets are fetched before the pool is modified, and the resolver is called without holding the lock.
+// If any secret can't be resolved, a MultiError is returned containing a SecretError for each secret, and the pool
+// is left unmodified. Keys that are changed while the secrets are fetched, i.e. via Set or Merge, keep their new
+// values.
+func (p *Pool) ResolveSecrets() error {
+	type secretRef struct {
+		section, key, ref, secret string
+	}
+
+	p.mu.Lock()
+	resolver := p.secretResolver
+	p.mu.Unlock()
+
+	refs := make([]secretRef, 0)
+	for sec, params := range p.load() {
+		for key, val := range params {
+			if strings.HasPrefix(val, SecretPrefix) {
+				refs = append(refs, secretRef{section: sec, key: key, ref: strings.TrimPrefix(val, SecretPrefix)})
+			}
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].section != refs[j].section {
+			return refs[i].section < refs[j].section
+		}
+		return refs[i].key < refs[j].key
+	})
+
+	errs := make([]error, 0)
+	for i, ref := range refs {
+		if resolver == nil {
+			errs = append(errs, SecretError{ref.key, ref.ref, errNoSecretResolver})
+			continue
+		}
+		secret, err := resolver(ref.ref)
+		if err != nil {
+			errs = append(errs, SecretError{ref.key, ref.ref, err})
+			continue
+		}
+		refs[i].secret = secret
+	}
+	if len(errs) > 0 {
+		return MultiError(errs)
+	}
+
+	values := make([]resolvedValue, 0, len(refs))
+	for _, ref := range refs {
+		values = append(values, resolvedValue{section: ref.section, key: ref.key, from: SecretPrefix + ref.ref, to: ref.secret})
+	}
+	p.applyResolved(values)
+	return nil
+}
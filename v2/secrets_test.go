@@ -0,0 +1,86 @@
+package configurama
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	secrets := map[string]string{
+		"db/password": "secret",
+		"api/token":   "abc123",
+	}
+	errNotFound := errors.New("not found")
+	resolver := func(ref string) (string, error) {
+		secret, ok := secrets[ref]
+		if !ok {
+			return "", errNotFound
+		}
+		return secret, nil
+	}
+
+	t.Run("it resolves secret references", func(t *testing.T) {
+		c := New(map[string]map[string]string{
+			"db":  {"user": "admin", "password": "secret://db/password"},
+			"api": {"token": "secret://api/token"},
+		})
+		c.SetSecretResolver(resolver)
+
+		verifyNil(t, c.ResolveSecrets())
+		verifyEqual(t, c.Raw(), map[string]map[string]string{
+			"db":  {"user": "admin", "password": "secret"},
+			"api": {"token": "abc123"},
+		})
+	})
+
+	t.Run("it keeps values changed while resolving", func(t *testing.T) {
+		c := New(map[string]map[string]string{
+			"db":  {"password": "secret://db/password"},
+			"api": {"token": "secret://api/token"},
+		})
+		c.SetSecretResolver(func(ref string) (string, error) {
+			if ref == "db/password" {
+				verifyNil(t, c.Set("api", "token", "changed"))
+			}
+			return resolver(ref)
+		})
+
+		verifyNil(t, c.ResolveSecrets())
+		verifyEqual(t, c.Raw(), map[string]map[string]string{
+			"db":  {"password": "secret"},
+			"api": {"token": "changed"},
+		})
+	})
+
+	t.Run("it leaves the pool unmodified on errors", func(t *testing.T) {
+		params := map[string]map[string]string{
+			"db":  {"password": "secret://db/password", "replica": "secret://db/replica"},
+			"api": {"token": "secret://api/unknown"},
+		}
+		c := New(params)
+		c.SetSecretResolver(resolver)
+
+		err := c.ResolveSecrets()
+		var multiErr MultiError
+		if !errors.As(err, &multiErr) || len(multiErr) != 2 {
+			t.Fatalf("expected MultiError with 2 errors, got %v", err)
+		}
+		if multiErr[0] != (SecretError{"token", "api/unknown", errNotFound}) {
+			t.Errorf("unexpected error: %v", multiErr[0])
+		}
+		if !errors.Is(multiErr[1], errNotFound) {
+			t.Errorf("expected error to wrap %v, got %v", errNotFound, multiErr[1])
+		}
+		verifyEqual(t, c.Raw(), params)
+	})
+
+	t.Run("it fails without a resolver", func(t *testing.T) {
+		c := New(map[string]map[string]string{"db": {"password": "secret://db/password"}})
+
+		err := c.ResolveSecrets()
+		var multiErr MultiError
+		if !errors.As(err, &multiErr) || len(multiErr) != 1 || !errors.Is(multiErr[0], errNoSecretResolver) {
+			t.Fatalf("expected MultiError with missing resolver, got %v", err)
+		}
+	})
+}
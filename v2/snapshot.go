@@ -0,0 +1,52 @@
+package configurama
+
+// MaxSnapshots is the maximum number of snapshots retained by a pool. When a new snapshot is saved and the limit
+// has been reached, the oldest snapshot is discarded.
+const MaxSnapshots = 16
+
+// SaveSnapshot saves the current contents of the configuration pool under the given label, i.e. a version number
+// or a deployment identifier, so it can be compared to other snapshots later via DiffSnapshots. Saving a snapshot
+// under an existing label replaces it. At most MaxSnapshots snapshots are retained.
+// Since the pool never modifies its contents in place, saving a snapshot doesn't copy any data.
+func (p *Pool) SaveSnapshot(label string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.snapshots == nil {
+		p.snapshots = make(map[string]map[string]map[string]string)
+	}
+	if _, ok := p.snapshots[label]; ok {
+		for i, l := range p.snapshotOrder {
+			if l == label {
+				p.snapshotOrder = append(p.snapshotOrder[:i:i], p.snapshotOrder[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(p.snapshotOrder) >= MaxSnapshots {
+		delete(p.snapshots, p.snapshotOrder[0])
+		p.snapshotOrder = p.snapshotOrder[1:]
+	}
+
+	p.snapshots[label] = p.load()
+	p.snapshotOrder = append(p.snapshotOrder, label)
+}
+
+// DiffSnapshots returns the sections and parameters of the snapshot labeled to that are not present, or have
+// different values, in the snapshot labeled from, in the same way as Compare does for pools. Parameters that were
+// removed between the two snapshots are not included, so use DiffSnapshots(to, from) to find those.
+// A NoSnapshotError is returned if either snapshot doesn't exist, i.e. because it has been discarded.
+func (p *Pool) DiffSnapshots(from, to string) (map[string]map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fromParams, ok := p.snapshots[from]
+	if !ok {
+		return nil, NoSnapshotError(from)
+	}
+	toParams, ok := p.snapshots[to]
+	if !ok {
+		return nil, NoSnapshotError(to)
+	}
+	return diff(toParams, fromParams), nil
+}
@@ -0,0 +1,46 @@
+package configurama
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSnapshots(t *testing.T) {
+	c := New(map[string]map[string]string{"db": {"host": "localhost", "port": "3306"}})
+	c.SaveSnapshot("v1")
+
+	verifyNil(t, c.Set("db", "host", "db.local"))
+	verifyNil(t, c.Merge(map[string]map[string]string{"cache": {"ttl": "5m"}}, Overwrite))
+	c.Unset("db", "port")
+	c.SaveSnapshot("v2")
+
+	changes, err := c.DiffSnapshots("v1", "v2")
+	verifyNil(t, err)
+	verifyEqual(t, changes, map[string]map[string]string{"db": {"host": "db.local"}, "cache": {"ttl": "5m"}})
+
+	removed, err := c.DiffSnapshots("v2", "v1")
+	verifyNil(t, err)
+	verifyEqual(t, removed, map[string]map[string]string{"db": {"host": "localhost", "port": "3306"}})
+
+	if _, err = c.DiffSnapshots("v1", "v3"); err != NoSnapshotError("v3") {
+		t.Errorf("expected error %v, got %v", NoSnapshotError("v3"), err)
+	}
+
+	// Saving under an existing label replaces the snapshot.
+	c.SaveSnapshot("v1")
+	changes, err = c.DiffSnapshots("v1", "v2")
+	verifyNil(t, err)
+	verifyEqual(t, changes, map[string]map[string]string{})
+
+	// The oldest snapshots are discarded once the limit is reached. The snapshot "v2" is now the oldest, since
+	// "v1" was saved again.
+	for i := 0; i < MaxSnapshots-1; i++ {
+		c.SaveSnapshot(strconv.Itoa(i))
+	}
+	if _, err = c.DiffSnapshots("v2", "v1"); err != NoSnapshotError("v2") {
+		t.Errorf("expected error %v, got %v", NoSnapshotError("v2"), err)
+	}
+	if _, err = c.DiffSnapshots("v1", "0"); err != nil {
+		t.Errorf("expected snapshot %q to be retained, got %v", "v1", err)
+	}
+}
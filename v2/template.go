@@ -0,0 +1,122 @@
+package configurama
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// RenderTemplates executes every value that contains "{{" as a text/template, with the other keys of the same
+// section as data, i.e. "https://{{.host}}:{{.port}}/" is rendered using the values of the keys host and port.
+// Templates may refer to keys that are templates themselves, in which case the rendered value is used. Values that
+// don't contain "{{" are left unchanged.
+// Templates are executed without any functions other than the builtins of text/template, so they have no access
+// to the filesystem, environment or external commands. Referring to a key that doesn't exist is an error.
+// Templates that don't resolve to a stable value, i.e. because they refer to each other in a cycle, are reported as
+// errors as well. All errors are returned as a MultiError, in which case the pool is left unmodified.
+// Templates are rendered from a snapshot of the pool without holding the lock. Keys that are changed while
+// rendering, i.e. via Set or Merge, keep their new values.
+func (p *Pool) RenderTemplates() error {
+	params := p.load()
+	sections := make([]string, 0, len(params))
+	for sec := range params {
+		sections = append(sections, sec)
+	}
+	sort.Strings(sections)
+
+	errs := make([]error, 0)
+	rendered := make(map[string]map[string]string)
+	for _, sec := range sections {
+		res, err := renderSection(sec, params[sec])
+		if err != nil {
+			errs = append(errs, err...)
+			continue
+		}
+		if res != nil {
+			rendered[sec] = res
+		}
+	}
+	if len(errs) > 0 {
+		return MultiError(errs)
+	}
+
+	values := make([]resolvedValue, 0)
+	for sec, res := range rendered {
+		for key, val := range res {
+			values = append(values, resolvedValue{section: sec, key: key, from: params[sec][key], to: val})
+		}
+	}
+	p.applyResolved(values)
+	return nil
+}
+
+// renderSection renders the templates in the given section, see RenderTemplates. It returns the rendered values,
+// or nil if the section contains no templates. Templates are rendered repeatedly against the values of the
+// previous round until no value changes. Since a chain of references can't be longer than the number of
+// templates, templates that still change after that many rounds can't be resolved.
+func renderSection(section string, keys map[string]string) (map[string]string, []error) {
+	templates := make(map[string]*template.Template)
+	names := make([]string, 0)
+	errs := make([]error, 0)
+	for key, val := range keys {
+		if !strings.Contains(val, "{{") {
+			continue
+		}
+		names = append(names, key)
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(val)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("section %q, key %q: %w", section, key, err))
+			continue
+		}
+		templates[key] = tmpl
+	}
+	sort.Strings(names)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	current := make(map[string]string, len(keys))
+	for key, val := range keys {
+		current[key] = val
+	}
+
+	var changed []string
+	for round := 0; round <= len(templates); round++ {
+		next := make(map[string]string, len(current))
+		for key, val := range current {
+			next[key] = val
+		}
+
+		changed = make([]string, 0)
+		for _, key := range names {
+			var b strings.Builder
+			if err := templates[key].Execute(&b, current); err != nil {
+				errs = append(errs, fmt.Errorf("section %q, key %q: %w", section, key, err))
+				continue
+			}
+			if next[key] = b.String(); next[key] != current[key] {
+				changed = append(changed, key)
+			}
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		if len(changed) == 0 {
+			res := make(map[string]string, len(templates))
+			for key := range templates {
+				res[key] = current[key]
+			}
+			return res, nil
+		}
+		current = next
+	}
+
+	for _, key := range changed {
+		errs = append(errs, fmt.Errorf("section %q, key %q: template does not resolve, possibly due to a cycle", section, key))
+	}
+	return nil, errs
+}
@@ -0,0 +1,87 @@
+package configurama
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"api": {
+			"host":   "localhost",
+			"port":   "8080",
+			"path":   "v1",
+			"scheme": "{{if eq .port \"443\"}}https{{else}}http{{end}}",
+			"url":    "{{.scheme}}://{{.host}}:{{.port}}/{{.path}}",
+			"plain":  "no template",
+		},
+		"other": {
+			"host": "example.com",
+		},
+	})
+
+	verifyNil(t, c.RenderTemplates())
+	verifyEqual(t, c.Raw(), map[string]map[string]string{
+		"api": {
+			"host":   "localhost",
+			"port":   "8080",
+			"path":   "v1",
+			"scheme": "http",
+			"url":    "http://localhost:8080/v1",
+			"plain":  "no template",
+		},
+		"other": {
+			"host": "example.com",
+		},
+	})
+
+	tt := map[string]struct {
+		params map[string]string
+		errs   int
+	}{
+		"cycle":          {map[string]string{"a": "{{.b}}", "b": "{{.a}}"}, 2},
+		"self-reference": {map[string]string{"a": "x{{.a}}"}, 1},
+		"missing key":    {map[string]string{"a": "{{.unknown}}"}, 1},
+		"parse error":    {map[string]string{"a": "{{.b", "b": "c"}, 1},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			c := New(map[string]map[string]string{"sec": tc.params})
+			err := c.RenderTemplates()
+			var multiErr MultiError
+			if !errors.As(err, &multiErr) || len(multiErr) != tc.errs {
+				t.Fatalf("expected MultiError with %d errors, got %v", tc.errs, err)
+			}
+			verifyEqual(t, c.Raw(), map[string]map[string]string{"sec": tc.params})
+		})
+	}
+}
+
+func TestRenderTemplatesConcurrent(t *testing.T) {
+	c := New(map[string]map[string]string{
+		"svc":   {"host": "localhost", "url": "http://{{.host}}/"},
+		"other": {},
+	})
+
+	var wg sync.WaitGroup
+	expected := map[string]string{}
+	for i := 0; i < 10; i++ {
+		key := "key" + strconv.Itoa(i)
+		expected[key] = strconv.Itoa(i)
+		wg.Add(1)
+		go func(key, val string) {
+			defer wg.Done()
+			verifyNil(t, c.Set("other", key, val))
+		}(key, expected[key])
+	}
+	verifyNil(t, c.RenderTemplates())
+	wg.Wait()
+
+	verifyEqual(t, c.Raw(), map[string]map[string]string{
+		"svc":   {"host": "localhost", "url": "http://localhost/"},
+		"other": expected,
+	})
+}
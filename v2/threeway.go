@@ -0,0 +1,96 @@
+package configurama
+
+import (
+	"errors"
+	"sort"
+)
+
+// Conflict represents a key that was changed in different ways by two versions of a configuration.
+// Keys that are missing from a version are represented by empty strings. Conflicts reported by MergeReport have
+// no Base, since there's no common ancestor.
+type Conflict struct {
+	Section, Key       string
+	Base, Mine, Theirs string
+}
+
+// ThreeWayMerge merges the changes made in mine and theirs, relative to their common ancestor base, into a new
+// configuration pool, in the same way as version control systems merge files. A key that was only changed in one
+// of mine and theirs gets the changed value, and a key that was changed in the same way in both gets that value.
+// Changes include adding and removing keys. A key that was changed differently in mine and theirs is a conflict,
+// and keeps the value from mine in the returned pool. Conflicts are returned sorted by section and key, so they
+// can be resolved, i.e. via Set. Sections are added and removed following the same rules.
+// None of the given pools are modified. An error is returned if any of the pools is nil.
+func ThreeWayMerge(base, mine, theirs *Pool) (*Pool, []Conflict, error) {
+	if base == nil || mine == nil || theirs == nil {
+		return nil, nil, errors.New("three-way merge requires three pools")
+	}
+	b, m, t := base.load(), mine.load(), theirs.load()
+
+	sections := make(map[string]bool)
+	for _, params := range []map[string]map[string]string{b, m, t} {
+		for sec := range params {
+			sections[sec] = true
+		}
+	}
+
+	res := make(map[string]map[string]string)
+	conflicts := make([]Conflict, 0)
+	for sec := range sections {
+		// A section that wasn't added or removed in theirs follows mine, otherwise theirs.
+		_, inBase := b[sec]
+		_, inMine := m[sec]
+		_, inTheirs := t[sec]
+		if inMine && inTheirs == inBase || inTheirs && inTheirs != inBase {
+			res[sec] = make(map[string]string)
+		}
+
+		keys := make(map[string]bool)
+		for _, params := range []map[string]string{b[sec], m[sec], t[sec]} {
+			for key := range params {
+				keys[key] = true
+			}
+		}
+
+		for key := range keys {
+			bv, mv, tv := lookupVersion(b, sec, key), lookupVersion(m, sec, key), lookupVersion(t, sec, key)
+
+			val := mv
+			switch {
+			case tv == bv, mv == tv:
+				// Only mine changed, or both changed in the same way.
+			case mv == bv:
+				val = tv
+			default:
+				conflicts = append(conflicts, Conflict{sec, key, bv.value, mv.value, tv.value})
+			}
+
+			if val.ok {
+				if res[sec] == nil {
+					res[sec] = make(map[string]string)
+				}
+				res[sec][key] = val.value
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Section != conflicts[j].Section {
+			return conflicts[i].Section < conflicts[j].Section
+		}
+		return conflicts[i].Key < conflicts[j].Key
+	})
+	return newPool(res), conflicts, nil
+}
+
+// version represents the value of a key in one version of a configuration. The field ok is false if the key
+// doesn't exist.
+type version struct {
+	value string
+	ok    bool
+}
+
+// lookupVersion returns the version of the given key in the given section of params.
+func lookupVersion(params map[string]map[string]string, section, key string) version {
+	value, ok := params[section][key]
+	return version{value, ok}
+}
@@ -0,0 +1,76 @@
+package configurama
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThreeWayMerge(t *testing.T) {
+	base := New(map[string]map[string]string{
+		"db": {
+			"host":    "localhost",
+			"port":    "3306",
+			"user":    "admin",
+			"timeout": "5s",
+			"pool":    "10",
+		},
+		"cache":  {"ttl": "5m"},
+		"legacy": {"enabled": "true"},
+	})
+	mine := New(map[string]map[string]string{
+		"db": {
+			"host":    "db.local", // Changed in mine only.
+			"port":    "3306",
+			"user":    "root", // Changed in both, differently.
+			"timeout": "10s",  // Changed in both, identically.
+			"pool":    "20",   // Changed in mine, removed in theirs.
+			"ssl":     "true", // Added in mine only.
+		},
+		"cache":  {"ttl": "5m"},
+		"legacy": {"enabled": "true"},
+	})
+	theirs := New(map[string]map[string]string{
+		"db": {
+			"host":    "localhost",
+			"port":    "3307", // Changed in theirs only.
+			"user":    "dba",
+			"timeout": "10s",
+		},
+		"cache": {"ttl": "5m"},
+		"queue": {"size": "100"}, // Added in theirs only.
+		// Removed in theirs only: legacy.
+	})
+
+	merged, conflicts, err := ThreeWayMerge(base, mine, theirs)
+	verifyNil(t, err)
+
+	verifyEqual(t, merged.Raw(), map[string]map[string]string{
+		"db": {
+			"host":    "db.local",
+			"port":    "3307",
+			"user":    "root",
+			"timeout": "10s",
+			"pool":    "20",
+			"ssl":     "true",
+		},
+		"cache": {"ttl": "5m"},
+		"queue": {"size": "100"},
+	})
+
+	expected := []Conflict{
+		{"db", "pool", "10", "20", ""},
+		{"db", "user", "admin", "root", "dba"},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("expected conflicts %v, got %v", expected, conflicts)
+	}
+
+	// None of the pools are modified.
+	if host, _ := base.Get("db", "host"); host != "localhost" {
+		t.Errorf("expected base to be unmodified, got host %q", host)
+	}
+
+	if _, _, err = ThreeWayMerge(base, nil, theirs); err == nil {
+		t.Error("expected error for nil pool, got nil")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/mkock/configurama/v2/yamlconfig
+
+go 1.17
+
+require (
+	github.com/mkock/configurama/v2 v2.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/mkock/configurama/v2 => ../
@@ -0,0 +1,135 @@
+// Package yamlconfig loads configuration pools from YAML. It's a separate module, so that only programs that need
+// YAML support depend on gopkg.in/yaml.v3.
+package yamlconfig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mkock/configurama/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load returns a new configuration pool containing the data parsed from the given YAML, see Parse.
+func Load(r io.Reader) (*configurama.Pool, error) {
+	params, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return configurama.New(params), nil
+}
+
+// Parse parses YAML consisting of a mapping of sections, each of which is a mapping of keys and values, i.e.
+// "db: {host: localhost, port: 3306}". Scalars are kept exactly as written, so "1.10" stays "1.10" and ".inf"
+// stays ".inf", except that null is converted into an empty string. Sequences of scalars are joined with commas,
+// and nested mappings are flattened using dotted keys, i.e. the key "master.host" in the section "db" for
+// "db: {master: {host: a}}". Empty YAML results in an empty pool.
+// Parse can be passed to configurama.NewFromFS and Pool.MergeGlob. An error is returned if the YAML isn't a
+// mapping of mappings, if it contains more than one document, if a sequence contains mappings or sequences, or if
+// a key occurs twice, including after flattening.
+func Parse(r io.Reader) (map[string]map[string]string, error) {
+	dec := yaml.NewDecoder(r)
+
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err == io.EOF {
+		return make(map[string]map[string]string), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to decode YAML: %w", err)
+	}
+	if err := dec.Decode(new(yaml.Node)); err != io.EOF {
+		return nil, fmt.Errorf("unable to decode YAML: unexpected data after the first document")
+	}
+
+	root := resolve(&doc)
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = resolve(root.Content[0])
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("unable to decode YAML: expected a mapping of sections, got %s", describeNode(root))
+	}
+
+	params := make(map[string]map[string]string, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		sec, val := root.Content[i].Value, resolve(root.Content[i+1])
+		if _, ok := params[sec]; ok {
+			return nil, fmt.Errorf("section %q: duplicate section", sec)
+		}
+		if val.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("section %q: expected a mapping of keys, got %s", sec, describeNode(val))
+		}
+		params[sec] = make(map[string]string, len(val.Content)/2)
+		if err := flattenKeys(params[sec], "", val); err != nil {
+			return nil, fmt.Errorf("section %q: %w", sec, err)
+		}
+	}
+	return params, nil
+}
+
+// flattenKeys adds the keys of the given mapping, prefixed with prefix, to params. Nested mappings are flattened
+// using dotted keys.
+func flattenKeys(params map[string]string, prefix string, mapping *yaml.Node) error {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, val := prefix+mapping.Content[i].Value, resolve(mapping.Content[i+1])
+
+		var s string
+		switch val.Kind {
+		case yaml.MappingNode:
+			if err := flattenKeys(params, key+".", val); err != nil {
+				return err
+			}
+			continue
+		case yaml.SequenceNode:
+			elems := make([]string, 0, len(val.Content))
+			for _, elem := range val.Content {
+				if elem = resolve(elem); elem.Kind != yaml.ScalarNode {
+					return fmt.Errorf("key %q: expected a list of scalar values, got a list containing %s", key, describeNode(elem))
+				}
+				elems = append(elems, scalarValue(elem))
+			}
+			s = strings.Join(elems, ",")
+		case yaml.ScalarNode:
+			s = scalarValue(val)
+		default:
+			return fmt.Errorf("key %q: unsupported value %s", key, describeNode(val))
+		}
+
+		if _, ok := params[key]; ok {
+			return fmt.Errorf("key %q: duplicate key", key)
+		}
+		params[key] = s
+	}
+	return nil
+}
+
+// resolve returns the node that the given node refers to if it's an alias, and the node itself otherwise.
+func resolve(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// scalarValue returns the text of the given scalar node as written, or an empty string for null.
+func scalarValue(node *yaml.Node) string {
+	if node.ShortTag() == "!!null" {
+		return ""
+	}
+	return node.Value
+}
+
+// describeNode returns a description of the kind of the given node, for use in error messages.
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!null" {
+			return "null"
+		}
+		return "a scalar value"
+	}
+	return "an unsupported value"
+}
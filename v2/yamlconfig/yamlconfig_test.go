@@ -0,0 +1,102 @@
+package yamlconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("valid YAML", func(t *testing.T) {
+		p, err := Load(strings.NewReader(`
+db:
+  host: localhost
+  port: 3306
+  ratio: 0.5
+  debug: true
+  password:
+  started: 2020-01-02T03:04:05Z
+  tags: [a, 1, false]
+  version: 1.10
+  limit: .inf
+  octal: 0o17
+  quoted: "007"
+  timeout: ~
+  master:
+    host: master.local
+    replica:
+      host: replica.local
+cache: {}
+`))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		expected := map[string]map[string]string{
+			"db": {
+				"host":                "localhost",
+				"port":                "3306",
+				"ratio":               "0.5",
+				"debug":               "true",
+				"password":            "",
+				"started":             "2020-01-02T03:04:05Z",
+				"tags":                "a,1,false",
+				"version":             "1.10",
+				"limit":               ".inf",
+				"octal":               "0o17",
+				"quoted":              "007",
+				"timeout":             "",
+				"master.host":         "master.local",
+				"master.replica.host": "replica.local",
+			},
+			"cache": {},
+		}
+		if actual := p.Raw(); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		p, err := Load(strings.NewReader("base: &base {host: localhost}\ndb: *base\n"))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		expected := map[string]map[string]string{"base": {"host": "localhost"}, "db": {"host": "localhost"}}
+		if actual := p.Raw(); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("empty YAML", func(t *testing.T) {
+		p, err := Load(strings.NewReader(""))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if actual := p.Raw(); len(actual) != 0 {
+			t.Errorf("expected empty pool, got %v", actual)
+		}
+	})
+
+	tt := map[string]string{
+		"malformed":                  "db: [",
+		"not a mapping":              "- db",
+		"section not a mapping":      "db: localhost",
+		"list of mappings":           "db:\n  hosts:\n    - host: a\n    - host: b\n",
+		"duplicate after flattening": "db:\n  master.host: a\n  master:\n    host: b\n",
+		"duplicate section":          "db: {host: a}\ndb: {host: b}\n",
+		"null document":              "~",
+		"multiple documents":         "db: {host: a}\n---\ncache: {ttl: 1m}\n",
+	}
+
+	for name, data := range tt {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(data)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	_, err := Load(strings.NewReader("db:\n  hosts:\n    - host: a\n"))
+	if err == nil || !strings.Contains(err.Error(), `key "hosts": expected a list of scalar values, got a list containing an object`) {
+		t.Errorf("expected descriptive error, got %v", err)
+	}
+}